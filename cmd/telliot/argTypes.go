@@ -5,6 +5,7 @@ package main
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -79,3 +80,32 @@ func (b *EthereumInt) String() string {
 func (b *EthereumInt) IsDefault() bool {
 	return true
 }
+
+// ExpiryTime is an absolute RFC3339 time or a duration from now.
+type ExpiryTime struct {
+	time.Time
+}
+
+func (e *ExpiryTime) Set(v string) error {
+	if d, err := time.ParseDuration(v); err == nil {
+		e.Time = time.Now().Add(d)
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return errors.Errorf("invalid expiry, expected a time like 2021-03-01T00:00:00Z or a duration like 720h:%v", v)
+	}
+	e.Time = t
+	return nil
+}
+
+func (e *ExpiryTime) String() string {
+	if e.IsZero() {
+		return ""
+	}
+	return e.Format(time.RFC3339)
+}
+
+func (e *ExpiryTime) IsDefault() bool {
+	return e.IsZero()
+}
@@ -7,11 +7,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,6 +25,7 @@ import (
 	"github.com/go-kit/kit/log/level"
 	cli "github.com/jawher/mow.cli"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	tellorCommon "github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/config"
@@ -28,6 +34,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/ops"
 	"github.com/tellor-io/telliot/pkg/rest"
 	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/tracker"
 	"github.com/tellor-io/telliot/pkg/util"
 )
 
@@ -38,6 +45,7 @@ var clt rpc.ETHClient
 var logLevel string
 var database db.DB
 var proxy db.DataServerProxy
+var configFile string
 
 func ExitOnError(err error, operation string) {
 	if err != nil {
@@ -46,7 +54,7 @@ func ExitOnError(err error, operation string) {
 	}
 }
 
-func setup() error {
+func setupLogging() error {
 	cfg := config.GetConfig()
 
 	err := util.SetupLoggingConfig(cfg.Logger)
@@ -55,6 +63,12 @@ func setup() error {
 	}
 
 	logLevel = cfg.LogLevel
+	return nil
+}
+
+// setup connects to the ethereum node for the commands that use it.
+func setup() error {
+	cfg := config.GetConfig()
 
 	if !cfg.EnablePoolWorker {
 		// Create an rpc client
@@ -72,7 +86,6 @@ func setup() error {
 		if err != nil {
 			return errors.Wrap(err, "creating account")
 		}
-		ctx = context.Background()
 		// Issue #55, halt if client is still syncing with Ethereum network
 		s, err := client.IsSyncing(ctx)
 		if err != nil {
@@ -89,7 +102,7 @@ func setup() error {
 	return nil
 }
 
-// migrateAndOpenDB migrates the tx costs and deletes the db.
+// migrateAndOpenDB migrates the tx costs, the whitelist and the manual data and deletes the db.
 // The DB is always deleted because the price avarages calculations
 // is not calculated properly between restarts.
 // TODO don't do this and just improve the price calculations.
@@ -109,6 +122,16 @@ func migrateAndOpenDB() (db.DB, error) {
 			txsGas = append(txsGas, txGas)
 		}
 	}
+	// Keep the miners whitelisted at runtime.
+	whitelist, err := DB.Get(db.WhitelistKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the whitelist for migration")
+	}
+	// Keep the manually entered values.
+	manualData, err := DB.Get(db.ManualDataKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the manual data for migration")
+	}
 	if err := DB.Close(); err != nil {
 		return nil, errors.Wrapf(err, "closing DB instance for migration")
 	}
@@ -123,6 +146,16 @@ func migrateAndOpenDB() (db.DB, error) {
 		txID := tellorCommon.PriceTXs + strconv.Itoa(i)
 		_ = DB.Put(txID, txGas)
 	}
+	if len(whitelist) > 0 {
+		if err := DB.Put(db.WhitelistKey, whitelist); err != nil {
+			return nil, errors.Wrapf(err, "migrating the whitelist")
+		}
+	}
+	if len(manualData) > 0 {
+		if err := DB.Put(db.ManualDataKey, manualData); err != nil {
+			return nil, errors.Wrapf(err, "migrating the manual data")
+		}
+	}
 
 	return DB, nil
 }
@@ -175,23 +208,39 @@ func App() *cli.Cli {
 
 	// This will get run before any of the commands
 	app.Before = func() {
-		ExitOnError(config.ParseConfig(*configPath), "parsing config file")
-		ExitOnError(setup(), "setting up")
+		configFile = *configPath
+		ExitOnError(config.ParseConfig(configFile), "parsing config file")
+		ExitOnError(setupLogging(), "setting up")
+		ctx = context.Background()
 	}
 
 	versionMessage := fmt.Sprintf(versionMessage, GitTag, GitHash)
 	app.Version("version", versionMessage)
 
-	app.Command("stake", "staking operations", stakeCmd(logSetup))
-	app.Command("transfer", "send TRB to address", moveCmd(ops.Transfer, logSetup))
-	app.Command("approve", "approve TRB to address", moveCmd(ops.Approve, logSetup))
-	app.Command("balance", "check balance of address", balanceCmd)
-	app.Command("dispute", "dispute operations", disputeCmd(logSetup))
-	app.Command("mine", "mine for TRB", mineCmd(logSetup))
-	app.Command("dataserver", "start an independent dataserver", dataserverCmd(logSetup))
+	app.Command("stake", "staking operations", withNode(stakeCmd(logSetup)))
+	app.Command("transfer", "send TRB to address", withNode(moveCmd(ops.Transfer, logSetup)))
+	app.Command("approve", "approve TRB to address", withNode(moveCmd(ops.Approve, logSetup)))
+	app.Command("balance", "check balance of address", withNode(balanceCmd))
+	app.Command("dispute", "dispute operations", withNode(disputeCmd(logSetup)))
+	app.Command("mine", "mine for TRB", withNode(mineCmd(logSetup)))
+	app.Command("dataserver", "start an independent dataserver", withNode(dataserverCmd(logSetup)))
+	app.Command("sources", "index source operations", sourcesCmd)
+	app.Command("manual", "manage the manually entered values", manualCmd)
+	app.Command("indexes", "index file operations", indexesCmd)
+	app.Command("backtest", "compare the values of the PSRs with historical on-chain values", backtestCmd)
 	return app
 }
 
+// withNode connects to the ethereum node before running the command.
+func withNode(init func(*cli.Cmd)) func(*cli.Cmd) {
+	return func(cmd *cli.Cmd) {
+		cmd.Before = func() {
+			ExitOnError(setup(), "setting up")
+		}
+		init(cmd)
+	}
+}
+
 func stakeCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 	return func(cmd *cli.Cmd) {
 		cmd.Command("deposit", "deposit TRB stake", simpleCmd(ops.Deposit, logSetup))
@@ -302,22 +351,27 @@ func mineCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 					// Start and wait for it to be ready.
 					ExitOnError(ds.Start(ctx), "starting data server")
 					<-ds.Ready()
+					reloadOnHangup(logger, ds)
 				}
 			}
 
 			level.Info(logger).Log("msg", "starting metrics server", "address", cfg.Mine.ListenHost+":"+strconv.Itoa(int(cfg.Mine.ListenPort)))
 			http.Handle("/metrics", promhttp.Handler())
-			srv, err := rest.Create(ctx, proxy, cfg.Mine.ListenHost, cfg.Mine.ListenPort)
+			srv, err := rest.Create(ctx, proxy, cfg.Mine.ListenHost, cfg.Mine.ListenPort, nil)
 			ExitOnError(err, "creating data server instance")
 			srv.Start()
 
 			// Start miner
-			v, err := proxy.Get(db.DisputeStatusKey)
+			v, err := proxy.Get(db.MinerKey(cfg.PublicAddress, db.DisputeStatusKey))
+			var wrongServer *db.WrongServerError
+			if errors.As(err, &wrongServer) {
+				ExitOnError(errors.Wrap(err, "set Mine.RemoteDBAddress or the Address of the Mine.RemoteDBServers entry to the public address of the data server"), "connecting to the data server")
+			}
 			if err != nil {
 				level.Warn(logger).Log("msg", "getting dispute status. Check if staked")
 			}
-			status, _ := hexutil.DecodeBig(string(v))
-			if status.Cmp(big.NewInt(1)) != 0 {
+			status, err := hexutil.DecodeBig(string(v))
+			if err != nil || status.Cmp(big.NewInt(1)) != 0 {
 				ExitOnError(errors.New("miner is not able to mine with current status"), "checking miner")
 			}
 			ch := make(chan os.Signal)
@@ -372,6 +426,7 @@ func mineCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 
 func dataserverCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 	return func(cmd *cli.Cmd) {
+		cmd.Command("whitelist", "manage the miners allowed to use the running data server", whitelistCmd)
 		cmd.Action = func() {
 			logger := logSetup(logLevel)
 			// Create os kill sig listener.
@@ -390,10 +445,19 @@ func dataserverCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 			ExitOnError(err, "starting data server")
 
 			<-ds.Ready()
+			reloadOnHangup(logger, ds)
 
 			http.Handle("/metrics", promhttp.Handler())
 			cfg := config.GetConfig()
-			srv, err := rest.Create(ctx, proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort)
+			var registry *db.MinerRegistry
+			if minerTracker, ok := proxy.(db.MinerTracker); ok {
+				registry = minerTracker.Registry()
+				prometheus.MustRegister(registry)
+			}
+			http.Handle(rest.APIPrefix, rest.CreateAPI(logger, cfg, database, registry))
+			tlsConfig, err := cfg.DataServer.TLS.ServerConfig()
+			ExitOnError(err, "loading data server TLS config")
+			srv, err := rest.Create(ctx, proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort, tlsConfig)
 			ExitOnError(err, "creating data server instance")
 			srv.Start()
 
@@ -431,6 +495,344 @@ func dataserverCmd(logSetup func(string) log.Logger) func(*cli.Cmd) {
 	}
 }
 
+// reloadOnHangup applies the changes of the index file and of the whitelist
+// in the config file to the data server every time the process receives a SIGHUP.
+func reloadOnHangup(logger log.Logger, ds *ops.DataServerOps) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			level.Info(logger).Log("msg", "reloading the index file")
+			if err := ds.ReloadIndexes(); err != nil {
+				level.Error(logger).Log("msg", "keeping the running indexes", "err", err)
+			}
+			reloader, ok := proxy.(db.WhitelistReloader)
+			if !ok {
+				continue
+			}
+			level.Info(logger).Log("msg", "reloading the whitelist", "file", configFile)
+			whitelist, err := config.ReadServerWhitelist(configFile)
+			if err == nil {
+				err = reloader.ReloadWhitelist(whitelist)
+			}
+			if err != nil {
+				level.Error(logger).Log("msg", "keeping the running whitelist", "err", err)
+			}
+		}
+	}()
+}
+
+func whitelistCmd(cmd *cli.Cmd) {
+	cmd.Command("add", "allow a miner to use the data server", whitelistChangeCmd(db.WhitelistAdmin.WhitelistAdd))
+	cmd.Command("remove", "revoke the access of a miner", whitelistChangeCmd(db.WhitelistAdmin.WhitelistRemove))
+	cmd.Command("list", "show the whitelisted miners and when they were last seen", whitelistListCmd)
+}
+
+func whitelistChangeCmd(f func(db.WhitelistAdmin, string) error) func(*cli.Cmd) {
+	return func(cmd *cli.Cmd) {
+		addr := ETHAddress{}
+		cmd.VarArg("ADDRESS", &addr, "ethereum public address")
+		cmd.Action = func() {
+			admin, err := db.OpenWhitelistAdmin()
+			ExitOnError(err, "connecting to the data server")
+			ExitOnError(f(admin, addr.String()), "changing the whitelist")
+		}
+	}
+}
+
+func whitelistListCmd(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		admin, err := db.OpenWhitelistAdmin()
+		ExitOnError(err, "connecting to the data server")
+		entries, err := admin.Whitelist()
+		ExitOnError(err, "listing the whitelist")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ADDRESS\tLAST SEEN\tREQUESTS\tREQUESTS/MIN\tREMOVABLE")
+		for _, e := range entries {
+			lastSeen := "never"
+			if e.LastSeen != nil {
+				lastSeen = e.LastSeen.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%v\n", e.Address, lastSeen, e.Requests, e.RequestRate, e.Removable)
+		}
+		ExitOnError(w.Flush(), "listing the whitelist")
+	}
+}
+
+func sourcesCmd(cmd *cli.Cmd) {
+	cmd.Command("report", "show the health of the sources of the running data server", sourcesReportCmd)
+}
+
+func sourcesReportCmd(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		client, err := rest.NewAPIClient(config.GetConfig())
+		ExitOnError(err, "connecting to the data server")
+		sources, err := client.Sources(ctx)
+		ExitOnError(err, "reading the sources")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tSYMBOLS\tSTATUS\tERROR RATE\tLATENCY\tSTALENESS\tDEVIATION\tREASON")
+		for _, s := range sources {
+			var symbols []string
+			for _, symbol := range s.Symbols {
+				// Skip the source specific variants of the symbols.
+				if !strings.Contains(symbol, "~") {
+					symbols = append(symbols, symbol)
+				}
+			}
+			status := "active"
+			if s.Health.Quarantined {
+				status = "quarantined"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\t%v\t%v\t%.2f%%\t%s\n",
+				s.Name,
+				strings.Join(symbols, ","),
+				status,
+				s.Health.ErrorRate*100,
+				s.Health.Latency.Round(time.Millisecond),
+				s.Health.Staleness.Round(time.Second),
+				s.Health.Deviation*100,
+				s.Health.Reason,
+			)
+		}
+		ExitOnError(w.Flush(), "reporting the sources")
+	}
+}
+
+func indexesCmd(cmd *cli.Cmd) {
+	cmd.Command("check", "validate the index file and request every source once", indexesCheckCmd)
+	cmd.Command("replay", "compute the values from the payloads of a record file", indexesReplayCmd)
+}
+
+// replayDB prints the value records stored by a replay.
+type replayDB struct {
+	db.DB
+	w io.Writer
+}
+
+func (r *replayDB) Put(key string, value []byte) error {
+	if strings.HasPrefix(key, db.QueriedRecordPrefix) {
+		if rec, err := db.DecodeValueRecord(value); err == nil {
+			fmt.Fprintf(r.w, "%s\t%s\t%v\t%.3f\t%d\n",
+				rec.Time.UTC().Format(time.RFC3339),
+				strings.TrimPrefix(key, db.QueriedRecordPrefix),
+				rec.Value.ToInt(),
+				rec.Confidence,
+				rec.Sources,
+			)
+		}
+	}
+	return r.DB.Put(key, value)
+}
+
+func indexesReplayCmd(cmd *cli.Cmd) {
+	cmd.Spec = "[--db] ARCHIVE"
+	archive := cmd.StringArg("ARCHIVE", "", "the file recorded with recordFile")
+	dbDir := cmd.StringOpt("db", "", "store the values in this DB instead of a temporary one")
+	cmd.Action = func() {
+		dir := *dbDir
+		if dir == "" {
+			tmp, err := ioutil.TempDir("", "replay")
+			ExitOnError(err, "creating the replay DB")
+			defer os.RemoveAll(tmp)
+			dir = tmp
+		}
+		DB, err := db.Open(dir)
+		ExitOnError(err, "opening the replay DB")
+		defer DB.Close()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tREQUEST ID\tVALUE\tCONFIDENCE\tSOURCES")
+		summary, err := tracker.Replay(ctx, config.GetConfig(), &replayDB{DB: DB, w: w}, *archive)
+		ExitOnError(err, "replaying the record file")
+		ExitOnError(w.Flush(), "replaying the record file")
+		fmt.Printf("replayed %d payloads, %d failed, %d skipped from sources that aren't in the index file\n", summary.Replayed, summary.Failed, summary.Skipped)
+	}
+}
+
+func indexesCheckCmd(cmd *cli.Cmd) {
+	offline := cmd.BoolOpt("offline", false, "only validate the index file without requesting the sources")
+	cmd.Action = func() {
+		checks, err := tracker.CheckIndexes(ctx, config.GetConfig(), *offline)
+		ExitOnError(err, "checking the index file")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if *offline {
+			fmt.Fprintln(w, "SOURCE\tSYMBOLS")
+		} else {
+			fmt.Fprintln(w, "SOURCE\tSYMBOLS\tVALUE\tLATENCY\tDEVIATION\tERROR")
+		}
+		var failed int
+		for _, c := range checks {
+			if *offline {
+				fmt.Fprintf(w, "%s\t%s\n", c.Identifier, strings.Join(c.Symbols, ","))
+				continue
+			}
+			if c.Err != nil {
+				failed++
+				fmt.Fprintf(w, "%s\t%s\t-\t%v\t-\t%v\n", c.Identifier, strings.Join(c.Symbols, ","), c.Latency.Round(time.Millisecond), c.Err)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%v\t%v\t%.2f%%\t\n",
+				c.Identifier,
+				strings.Join(c.Symbols, ","),
+				c.Value,
+				c.Latency.Round(time.Millisecond),
+				c.Deviation*100,
+			)
+		}
+		ExitOnError(w.Flush(), "checking the index file")
+		if failed > 0 {
+			ExitOnError(errors.Errorf("%d of %d sources failed", failed, len(checks)), "checking the index file")
+		}
+	}
+}
+
+func backtestCmd(cmd *cli.Cmd) {
+	cmd.Spec = "--events (--archive | --saved) [--request-ids]"
+	eventsFile := cmd.StringOpt("events", "", "JSON file with the exported NewValue and NonceSubmitted events")
+	archive := cmd.StringOpt("archive", "", "compute the values from a file recorded with recordFile")
+	saved := cmd.StringOpt("saved", "", "compute the values from the value history saved by the trackers")
+	requestIDs := cmd.StringOpt("request-ids", "", "comma separated request IDs to test, all the request IDs of the events by default")
+	cmd.Action = func() {
+		events, err := tracker.ReadBacktestEvents(*eventsFile)
+		ExitOnError(err, "reading the events")
+		if *requestIDs != "" {
+			ids := make(map[int]bool)
+			for _, s := range strings.Split(*requestIDs, ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(s))
+				ExitOnError(errors.Wrapf(err, "invalid request ID:%v", s), "parsing the request IDs")
+				ids[id] = true
+			}
+			var filtered []*tracker.BacktestEvent
+			for _, e := range events {
+				if ids[e.RequestID] {
+					filtered = append(filtered, e)
+				}
+			}
+			events = filtered
+		}
+
+		var results []*tracker.BacktestResult
+		if *archive != "" {
+			tmp, err := ioutil.TempDir("", "backtest")
+			ExitOnError(err, "creating the backtest DB")
+			defer os.RemoveAll(tmp)
+			DB, err := db.Open(tmp)
+			ExitOnError(err, "opening the backtest DB")
+			defer DB.Close()
+			results, err = tracker.BacktestArchive(ctx, config.GetConfig(), DB, *archive, events)
+			ExitOnError(err, "backtesting the record file")
+		} else {
+			results, err = tracker.BacktestSaved(config.GetConfig(), *saved, events)
+			ExitOnError(err, "backtesting the value history")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "REQUEST ID\tEVENT\tEVENTS\tMISSING\tMEAN ERROR\tP50\tP90\tP99\tMAX\tOUTSIDE THRESHOLD")
+		for _, r := range results {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%+.3f%%\t%.3f%%\t%.3f%%\t%.3f%%\t%.3f%%\t%d\n",
+				r.RequestID,
+				r.Event,
+				r.Events,
+				r.Missing,
+				r.MeanError*100,
+				r.P50*100,
+				r.P90*100,
+				r.P99*100,
+				r.Max*100,
+				r.Outside,
+			)
+		}
+		ExitOnError(w.Flush(), "backtesting")
+	}
+}
+
+func manualCmd(cmd *cli.Cmd) {
+	cmd.Command("set", "enter the value of a request ID", manualSetCmd)
+	cmd.Command("clear", "remove the value of a request ID", manualClearCmd)
+	cmd.Command("list", "show the entered values and when they expire", manualListCmd)
+}
+
+// openManualData changes the manual data directly in the DB when it isn't used
+// by a running miner or data server and through the running data server otherwise.
+func openManualData() (db.ManualDataAdmin, func(), error) {
+	DB, err := db.Open(config.GetConfig().DBFile)
+	if err != nil {
+		admin, err := db.OpenManualDataAdmin()
+		return admin, func() {}, err
+	}
+	closeDB := func() {
+		if err := DB.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "closing the DB: %v\n", err)
+		}
+	}
+	admin, err := db.OpenLocalManualData(DB)
+	if err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+	return admin, closeDB, nil
+}
+
+func manualSetCmd(cmd *cli.Cmd) {
+	cmd.Spec = "--expires [--granularity] REQUEST_ID VALUE"
+	requestID := cmd.IntArg("REQUEST_ID", 0, "request ID")
+	value := cmd.StringArg("VALUE", "", "decimal value, e.g. 111.683")
+	expires := ExpiryTime{}
+	cmd.VarOpt("expires", &expires, "when the value expires, a time like 2021-03-01T00:00:00Z or a duration from now like 720h")
+	granularity := cmd.IntOpt("granularity", 0, "granularity of a request ID that has no PSR")
+	cmd.Action = func() {
+		if *requestID < 1 {
+			ExitOnError(errors.Errorf("invalid request id:%v", *requestID), "entering the value")
+		}
+		g := int64(*granularity)
+		if psr, ok := tracker.PSRs[*requestID]; ok {
+			if g != 0 && g != psr.Granularity() {
+				ExitOnError(errors.Errorf("request id %v has a granularity of %v", *requestID, psr.Granularity()), "entering the value")
+			}
+			g = psr.Granularity()
+		} else if g == 0 {
+			ExitOnError(errors.Errorf("request id %v has no PSR so --granularity is required", *requestID), "entering the value")
+		}
+		e, err := db.NewManualEntry(uint64(*requestID), *value, g, expires.Time)
+		ExitOnError(err, "entering the value")
+		admin, closeDB, err := openManualData()
+		ExitOnError(err, "opening the manual data")
+		defer closeDB()
+		ExitOnError(admin.ManualSet(e), "entering the value")
+	}
+}
+
+func manualClearCmd(cmd *cli.Cmd) {
+	requestID := cmd.IntArg("REQUEST_ID", 0, "request ID")
+	cmd.Action = func() {
+		admin, closeDB, err := openManualData()
+		ExitOnError(err, "opening the manual data")
+		defer closeDB()
+		ExitOnError(admin.ManualClear(uint64(*requestID)), "clearing the value")
+	}
+}
+
+func manualListCmd(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		admin, closeDB, err := openManualData()
+		ExitOnError(err, "opening the manual data")
+		defer closeDB()
+		entries, err := admin.ManualData()
+		ExitOnError(err, "listing the manual data")
+		now := time.Now()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "REQUEST ID\tVALUE\tDECIMAL\tEXPIRES\tSIGNER\tSTATUS")
+		for _, e := range entries {
+			status := "active"
+			if e.Expired(now) {
+				status = "expired"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", e.RequestID, e.Value.ToInt().String(), e.Decimal, e.Expires.Format(time.RFC3339), e.Signer, status)
+		}
+		ExitOnError(w.Flush(), "listing the manual data")
+	}
+}
+
 func main() {
 	// Programming is easy. Just create an App() and run it!!!!!
 	app := App()
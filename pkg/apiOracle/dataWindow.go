@@ -34,7 +34,7 @@ func (w *Window) Clear() {
 }
 
 func (w *Window) Trim() {
-	now := time.Now()
+	now := clck.Now()
 	n := len(w.buffer)
 	for w.num > 0 {
 		v := w.buffer[w.start]
@@ -83,7 +83,7 @@ func (w *Window) WithinRange(at time.Time, delta time.Duration) []*PriceStamp {
 }
 
 func (w *Window) Insert(x *PriceStamp) {
-	now := time.Now()
+	now := clck.Now()
 	t := x.Created
 	latest := w.Latest()
 	// Ignore if too old already or if older than current newest.
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/util"
@@ -22,6 +23,9 @@ var logger = util.NewLogger("apiOracle", "valueOracle")
 var valueHistory map[string]*Window
 var valueHistoryMutex sync.RWMutex
 
+// clck trims the windows, values older than their keep duration are dropped.
+var clck clock.Clock = clock.New()
+
 func GetNearestTwoRequestValue(id string, at time.Time) (before, after *PriceStamp) {
 	valueHistoryMutex.RLock()
 	defer valueHistoryMutex.RUnlock()
@@ -58,6 +62,10 @@ func SetRequestValue(id string, at time.Time, info PriceInfo) {
 
 func writeOutHistory() {
 	valueHistoryMutex.Lock()
+	if memoryOnly {
+		valueHistoryMutex.Unlock()
+		return
+	}
 	for _, v := range valueHistory {
 		v.Trim()
 	}
@@ -87,6 +95,39 @@ func writeOutHistory() {
 	}
 }
 
+// InitMemoryValueOracle replaces the value history with an empty one that isn't saved to disk
+// and trims the values with the given clock, e.g. to replay recorded values.
+func InitMemoryValueOracle(c clock.Clock) {
+	valueHistoryMutex.Lock()
+	defer valueHistoryMutex.Unlock()
+	valueHistory = make(map[string]*Window)
+	clck = c
+	memoryOnly = true
+}
+
+// LoadValueHistory replaces the value history with one saved to disk, e.g. by another process.
+// The windows keep the values within their keep duration of the given clock.
+// The loaded history isn't saved to disk.
+func LoadValueHistory(path string, c clock.Clock) error {
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "read value history file:%v", path)
+	}
+	valueHistoryMutex.Lock()
+	defer valueHistoryMutex.Unlock()
+	clck = c
+	memoryOnly = true
+	history := make(map[string]*Window)
+	if err := json.Unmarshal(byteValue, &history); err != nil {
+		return errors.Wrapf(err, "decoding value history file:%v", path)
+	}
+	valueHistory = history
+	return nil
+}
+
+// memoryOnly stops saving the value history to disk.
+var memoryOnly bool
+
 func EnsureValueOracle() error {
 	if valueHistory != nil {
 		return nil
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
@@ -58,12 +60,209 @@ type GPUConfig struct {
 type DataServer struct {
 	ListenHost string
 	ListenPort uint
+	// TLS enables serving the miners over TLS.
+	// When CAFile is set the miners must present a client certificate signed by it.
+	TLS TLS
+	// API configures the read-only JSON API.
+	API API
+	// SlotClaims is the maximum number of miners allowed to submit a solution
+	// for the same challenge. Zero grants every claim.
+	SlotClaims int
+	// SlotClaimTimeout releases a claim so that another miner can take it.
+	SlotClaimTimeout Duration
+}
+
+// RemoteDB is a data server used by remote miners.
+type RemoteDB struct {
+	Host string
+	Port uint
+	// Public address of the data server.
+	// Defaults to Mine.RemoteDBAddress.
+	Address string
+}
+
+// TLS holds the certificates used to secure the connection between the miners and the data server.
+type TLS struct {
+	// CertFile and KeyFile are the PEM encoded certificate and private key presented to the other side.
+	CertFile string
+	KeyFile  string
+	// CAFile is a PEM encoded CA certificate used to verify the other side.
+	CAFile string
+}
+
+// Enabled reports whether any TLS option is set.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || t.CAFile != ""
+}
+
+// ServerConfig returns the tls config for a server or nil when TLS is not enabled.
+func (t TLS) ServerConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, errors.New("TLS server requires both a certificate and a key file")
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading TLS certificate")
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pool, err := t.caPool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientConfig returns the tls config for a client or nil when TLS is not enabled.
+func (t TLS) ClientConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pool, err := t.caPool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading TLS client certificate")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func (t TLS) caPool() (*x509.CertPool, error) {
+	ca, err := ioutil.ReadFile(t.CAFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read CA file:%v", t.CAFile)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.Errorf("no valid certificates in CA file:%v", t.CAFile)
+	}
+	return pool, nil
+}
+
+type API struct {
+	// RateLimit is the number of requests per second allowed for each client.
+	// Zero disables the rate limiting.
+	RateLimit float64
+	// RateBurst is the maximum number of requests a client can make at once.
+	RateBurst int
+}
+
+// SubmitGuard holds back solutions with values that changed too much since
+// the last values accepted by the oracle. Changes are relative to the last value.
+type SubmitGuard struct {
+	// MaxChange applies to all request IDs without a more specific limit.
+	// Zero disables the guard for them.
+	MaxChange float64
+	// RequestIDs sets the max change of single request IDs.
+	RequestIDs map[int]float64
+	// Symbols sets the max change of all request IDs computed from a symbol, e.g. "ETH/USD".
+	Symbols map[string]float64
+	// Classes sets the max change of all request IDs of a PSR class: spot, average, eod or manual.
+	Classes map[string]float64
+}
+
+// SourceHealth quarantines the index sources that fail, respond slowly, stop updating or
+// disagree with the other sources of their symbols. Quarantined sources are still queried
+// and rejoin the indexes as soon as they are healthy again. Zero disables a threshold.
+type SourceHealth struct {
+	// Window is the number of latest requests to a source its health is computed from.
+	Window int
+	// MaxErrorRate is the maximum fraction of failed requests in the window.
+	MaxErrorRate float64
+	// MaxLatency is the maximum average duration of the requests in the window.
+	MaxLatency Duration
+	// MaxStaleness is how much older than its interval the latest value of a source can be.
+	MaxStaleness Duration
+	// MaxDeviation is the maximum average relative deviation in the window from
+	// the median of the other sources of the same symbols.
+	MaxDeviation float64
+}
+
+// SourceRateLimit limits the requests to the hosts of the index sources.
+// All sources of a host share its limit.
+type SourceRateLimit struct {
+	// Rate is the number of requests per second to each host.
+	// Zero disables the rate limiting.
+	Rate float64
+	// Burst is the maximum number of requests to a host at once.
+	Burst int
+	// Hosts overrides the limits of single hosts, e.g. "api.binance.com".
+	Hosts map[string]HostRateLimit
+}
+
+type HostRateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Fetch configures the HTTP requests to the index sources and the remote data servers.
+type Fetch struct {
+	// Proxy is the URL of the proxy for all requests.
+	// Defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env variables.
+	Proxy string
+	// MaxResponseSize is the largest response body in bytes.
+	// Zero disables the limit.
+	MaxResponseSize int64
+	// MaxAttempts is how many times a failed request is sent.
+	// Zero retries until the request times out.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry, it doubles with every retry up to MaxBackoff.
+	MinBackoff Duration
+	MaxBackoff Duration
 }
 
 type Mine struct {
 	// Connect to this remote DB.
 	RemoteDBHost string
 	RemoteDBPort uint
+	// Public address of the remote data server. Requests are signed
+	// for this address so they can't be replayed against another data server.
+	// Defaults to the miner public address.
+	RemoteDBAddress string
+	// RemoteDBServers replaces RemoteDBHost and RemoteDBPort to mine with several data servers.
+	// The first healthy server is used and the others take over when it fails.
+	RemoteDBServers []RemoteDB
+	// RemoteDBMaxDisagreement enables comparing the challenge and the values of all healthy
+	// data servers. Lookups fail when the values differ by more than this relative amount.
+	// Zero disables the comparison.
+	RemoteDBMaxDisagreement float64
+	// RemoteDBTLS enables connecting to the remote DB over TLS.
+	// When CAFile is set only server certificates signed by it are trusted.
+	RemoteDBTLS TLS
+	// RemoteDBStream subscribes to values pushed by the remote DB
+	// instead of only polling it every MiningInterruptCheckInterval.
+	RemoteDBStream bool
+	// SubmitGuard compares the values with the last on-chain values before submitting.
+	SubmitGuard SubmitGuard
+	// MaxValueAge is how old a value can be before the miner stops mining and submitting it.
+	// Zero disables the check.
+	MaxValueAge Duration
+	// MaxDispersion halts mining challenges with a request ID whose sources deviate more than
+	// this relative amount from their median. Zero disables the check.
+	MaxDispersion float64
+	// MinValueConfidence is the minimum confidence of a value for the miner to mine and submit it.
+	// Zero disables the check.
+	MinValueConfidence float64
 	// Exposes metrics on this host and port.
 	ListenHost string
 	ListenPort uint
@@ -73,6 +272,9 @@ type Mine struct {
 type Config struct {
 	Mine                         Mine
 	DataServer                   DataServer
+	SourceHealth                 SourceHealth
+	SourceRateLimit              SourceRateLimit
+	Fetch                        Fetch
 	ContractAddress              string                `json:"contractAddress"`
 	PublicAddress                string                `json:"publicAddress"`
 	EthClientTimeout             uint                  `json:"ethClientTimeout"`
@@ -81,6 +283,7 @@ type Config struct {
 	Trackers                     map[string]bool       `json:"trackers"`
 	DBFile                       string                `json:"dbFile"`
 	FetchTimeout                 Duration              `json:"fetchTimeout"`
+	RecordFile                   string                `json:"recordFile"` // When set the payloads of all index sources are appended to this archive.
 	MinConfidence                float64               `json:"minConfidence"`
 	MiningInterruptCheckInterval Duration              `json:"miningInterruptCheckInterval"`
 	GasMultiplier                float32               `json:"gasMultiplier"`
@@ -88,6 +291,7 @@ type Config struct {
 	NumProcessors                int                   `json:"numProcessors"`
 	Heartbeat                    Duration              `json:"heartbeat"`
 	ServerWhitelist              []string              `json:"serverWhitelist"`
+	ManualDataSigners            []string              `json:"manualDataSigners"` // When set only manual entries signed by these addresses are used.
 	GPUConfig                    map[string]*GPUConfig `json:"gpuConfig"`
 	EnablePoolWorker             bool                  `json:"enablePoolWorker"`
 	Worker                       string                `json:"worker"`
@@ -96,8 +300,9 @@ type Config struct {
 	ConfigFolder                 string                `json:"configFolder"`
 	LogLevel                     string                `json:"logLevel"`
 	Logger                       map[string]string     `json:"logger"`
-	DisputeTimeDelta             Duration              `json:"disputeTimeDelta"` // Ignore data further than this away from the value we are checking.
-	DisputeThreshold             float64               `json:"disputeThreshold"` // Maximum allowed relative difference between observed and submitted value.
+	DisputeTimeDelta             Duration              `json:"disputeTimeDelta"`     // Ignore data further than this away from the value we are checking.
+	DisputeThreshold             float64               `json:"disputeThreshold"`     // Maximum allowed relative difference between observed and submitted value.
+	DisputeMinConfidence         float64               `json:"disputeMinConfidence"` // Minimum confidence of the observed values compared with the submitted value.
 	// Minimum percent of profit when submitting a solution.
 	// For example if the tx cost is 0.01 ETH and current reward is 0.02 ETH
 	// a ProfitThreshold of 200% or more will wait until the reward is increased or
@@ -117,15 +322,42 @@ var defaultConfig = Config{
 	MinConfidence:    0.2,
 	MinSubmitPeriod:  Duration{15 * time.Minute},
 	DisputeThreshold: 0.01,
+	// Values used to check submissions need more confidence than the values submitted by the miners.
+	DisputeMinConfidence: 0.8,
 	Mine: Mine{
-		ListenHost:   "localhost",
-		ListenPort:   9090,
-		RemoteDBHost: "localhost",
-		RemoteDBPort: 5000,
+		ListenHost:     "localhost",
+		ListenPort:     9090,
+		RemoteDBHost:   "localhost",
+		RemoteDBPort:   5000,
+		RemoteDBStream: true,
+		MaxValueAge:    Duration{10 * time.Minute},
+		MaxDispersion:  0.1,
+		SubmitGuard: SubmitGuard{
+			MaxChange: 0.5,
+		},
 	},
 	DataServer: DataServer{
 		ListenHost: "localhost",
 		ListenPort: 5000,
+		API: API{
+			RateLimit: 10,
+			RateBurst: 20,
+		},
+		SlotClaimTimeout: Duration{2 * time.Minute},
+	},
+	SourceHealth: SourceHealth{
+		Window:       20,
+		MaxErrorRate: 0.5,
+		MaxStaleness: Duration{30 * time.Minute},
+		MaxDeviation: 0.1,
+	},
+	SourceRateLimit: SourceRateLimit{
+		Burst: 5,
+	},
+	Fetch: Fetch{
+		MaxResponseSize: 10 << 20,
+		MinBackoff:      Duration{500 * time.Millisecond},
+		MaxBackoff:      Duration{10 * time.Second},
 	},
 	Heartbeat:                    Duration{15 * time.Second},
 	DBFile:                       "db",
@@ -167,6 +399,9 @@ var defaultConfig = Config{
 const PrivateKeyEnvName = "ETH_PRIVATE_KEY"
 const NodeURLEnvName = "NODE_URL"
 
+// APITokenEnvName is the optional bearer token required by the data server JSON API.
+const APITokenEnvName = "API_TOKEN"
+
 // ParseConfig and set a shared config entry.
 func ParseConfig(path string) error {
 	data, err := ioutil.ReadFile(path)
@@ -177,6 +412,22 @@ func ParseConfig(path string) error {
 	return ParseConfigBytes(data)
 }
 
+// ReadServerWhitelist reads the miners whitelisted in the config file
+// so that a running data server can apply changes to them.
+func ReadServerWhitelist(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open config file:%v", path)
+	}
+	var cfg struct {
+		ServerWhitelist []string `json:"serverWhitelist"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, "parse config json")
+	}
+	return cfg.ServerWhitelist, nil
+}
+
 func ParseConfigBytes(data []byte) error {
 	err := json.Unmarshal(data, &defaultConfig)
 	config := &defaultConfig
@@ -5,6 +5,8 @@ package dataServer
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -43,6 +45,14 @@ func CreateServer(
 	if err != nil {
 		return nil, errors.Wrapf(err, "creating data server tracker runner instance")
 	}
+	logger = log.With(logger, "component", "data server")
+	missing, err := missingManualData(config, DB, time.Now())
+	if err != nil {
+		level.Warn(logger).Log("msg", "checking the manual data", "err", err)
+	}
+	for _, id := range missing {
+		level.Warn(logger).Log("msg", "request id has no usable manual value and isn't mined until one is entered with telliot manual set", "requestID", id)
+	}
 	// Make sure channel buffer size 1 since there is no guarantee that anyone
 	// Would be listening to the channel
 	ready := make(chan bool, 1)
@@ -54,10 +64,36 @@ func CreateServer(
 		Stopped:      true,
 		runnerExitCh: nil,
 		readyChannel: ready,
-		logger:       log.With(logger, "component", "data server")}, nil
+		logger:       logger}, nil
 
 }
 
+// missingManualData returns the request IDs that only have manually entered values
+// and no entry that can be submitted at the given time.
+func missingManualData(cfg *config.Config, DB db.DB, now time.Time) ([]int, error) {
+	entries, err := db.StoredManualData(DB)
+	if err != nil {
+		return nil, err
+	}
+	var missing []int
+	for id, psr := range tracker.PSRs {
+		if _, ok := psr.(*tracker.Manual); !ok {
+			continue
+		}
+		usable := false
+		for _, e := range entries {
+			if e.RequestID == uint64(id) && !e.Expired(now) && e.Verify(cfg.ManualDataSigners) == nil {
+				usable = true
+			}
+		}
+		if !usable {
+			missing = append(missing, id)
+		}
+	}
+	sort.Ints(missing)
+	return missing, nil
+}
+
 // Start the data server and all underlying resources.
 func (ds *DataServer) Start(ctx context.Context, exitCh chan int) error {
 	ds.exitCh = exitCh
@@ -82,6 +118,11 @@ func (ds *DataServer) Start(ctx context.Context, exitCh chan int) error {
 	return nil
 }
 
+// ReloadIndexes applies the changes of the index file without a restart.
+func (ds *DataServer) ReloadIndexes() error {
+	return ds.runner.ReloadIndexes()
+}
+
 // Ready provides notification channel that data from trackers is ready for use.
 func (ds *DataServer) Ready() chan bool {
 	return ds.readyChannel
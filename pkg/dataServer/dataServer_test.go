@@ -5,6 +5,7 @@ package dataServer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -52,7 +53,7 @@ func TestDataServer(t *testing.T) {
 	testutil.Ok(t, err, "creating server in test")
 	testutil.Ok(t, ds.Start(ctx, exitCh), "starting server")
 
-	srv, err := rest.Create(ctx, proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort)
+	srv, err := rest.Create(ctx, proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort, nil)
 	testutil.Ok(t, err)
 	srv.Start()
 
@@ -65,3 +66,27 @@ func TestDataServer(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	testutil.Assert(t, ds.Stopped, "Did not stop server")
 }
+
+func TestMissingManualData(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+
+	missing, err := missingManualData(cfg, DB, time.Now())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []int{41}, missing)
+
+	e, err := db.NewManualEntry(41, "111.683", 1000, time.Now().Add(time.Hour))
+	testutil.Ok(t, err)
+	data, err := json.Marshal([]*db.ManualEntry{e})
+	testutil.Ok(t, err)
+	testutil.Ok(t, DB.Put(db.ManualDataKey, data))
+	missing, err = missingManualData(cfg, DB, time.Now())
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(missing))
+
+	// Expired entries can't be submitted.
+	missing, err = missingManualData(cfg, DB, time.Now().Add(2*time.Hour))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []int{41}, missing)
+}
@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+
+	"github.com/pkg/errors"
 )
 
 func encode(buf *bytes.Buffer, any interface{}) error {
@@ -26,10 +28,11 @@ func encodeBytes(buf *bytes.Buffer, bts []byte) error {
 }
 
 // decodeBytes from the given buffer by reading a length and then the bytes.
-func decodeBytes(buf io.Reader) ([]byte, error) {
-
-	len := uint32(0)
-	if err := decode(buf, &len); err != nil {
+// The length is checked against the given max before allocating anything
+// so that a peer can't make us allocate arbitrary amounts of memory.
+func decodeBytes(buf io.Reader, max uint32) ([]byte, error) {
+	len, err := decodeLen(buf, max)
+	if err != nil {
 		return nil, err
 	}
 	bts := make([]byte, len)
@@ -39,14 +42,26 @@ func decodeBytes(buf io.Reader) ([]byte, error) {
 	return bts, nil
 }
 
+// decodeLen reads a length prefix and makes sure it doesn't exceed the given max.
+func decodeLen(buf io.Reader, max uint32) (uint32, error) {
+	len := uint32(0)
+	if err := decode(buf, &len); err != nil {
+		return 0, err
+	}
+	if len > max {
+		return 0, errors.Errorf("length %d exceeds the limit of %d", len, max)
+	}
+	return len, nil
+}
+
 // encodeString encodes the length/bytes of the given string to the buffer.
 func encodeString(buf *bytes.Buffer, str string) error {
 	return encodeBytes(buf, []byte(str))
 }
 
 // decodeString decodes the length/bytes of a string from the given stream.
-func decodeString(buf io.Reader) (string, error) {
-	bts, err := decodeBytes(buf)
+func decodeString(buf io.Reader, max uint32) (string, error) {
+	bts, err := decodeBytes(buf, max)
 	if err != nil {
 		return "", err
 	}
@@ -3,7 +3,11 @@
 
 package db
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 const (
 	// BalanceKey is the key to store/lookup account balance.
@@ -38,6 +42,29 @@ const (
 	LastNewValueKey    = "lastnewvalue"
 	LastSubmissionKey  = "last_submission"
 	TimeOutKey         = "time_out"
+
+	// QueriedRecordPrefix is for the value records that hold the request values together
+	// with when and how confidently they were computed. The plain values are still stored
+	// under QueriedValuePrefix for the miners that don't read the records.
+	QueriedRecordPrefix = "qr_"
+
+	// SlotClaimKey is written by a miner with the challenge it is about to submit a solution for.
+	// The data server replaces the value with 0x1 when the claim is granted and 0x0 otherwise.
+	SlotClaimKey = "slot_claim"
+
+	// WhitelistKey stores the miners added to the data server whitelist at runtime.
+	WhitelistKey = "whitelist"
+	// WhitelistAddKey and WhitelistRemoveKey are written with the data server key to change the whitelist.
+	WhitelistAddKey    = "whitelist_add"
+	WhitelistRemoveKey = "whitelist_remove"
+
+	// ManualDataKey stores the values entered by the operators for request IDs
+	// that aren't computed from the index sources.
+	ManualDataKey = "manual_data"
+	// ManualSetKey and ManualClearKey are written with the data server key
+	// or the key of an authorized signer to change the manual entries.
+	ManualSetKey   = "manual_set"
+	ManualClearKey = "manual_clear"
 )
 
 var knownKeys map[string]bool
@@ -64,6 +91,7 @@ func initKeyLook() {
 		LastNewValueKey:     true,
 		LastSubmissionKey:   true,
 		TimeOutKey:          true,
+		ManualDataKey:       true,
 	}
 }
 func isKnownKey(key string) bool {
@@ -72,9 +100,26 @@ func isKnownKey(key string) bool {
 	}
 	if !knownKeys[key] {
 		if !strings.HasPrefix(key, QueryMetadataPrefix) &&
-			!strings.HasPrefix(key, QueriedValuePrefix) {
+			!strings.HasPrefix(key, QueriedValuePrefix) &&
+			!strings.HasPrefix(key, QueriedRecordPrefix) {
 			return false
 		}
 	}
 	return true
 }
+
+// MinerKey returns the key of a value that is saved separately for every miner address.
+func MinerKey(address string, key string) string {
+	return strings.ToLower(common.HexToAddress(address).Hex()) + "-" + key
+}
+
+// isMinerKey checks whether the key is a known key of the given miner.
+func isMinerKey(miner string, key string) bool {
+	if miner == "" || !strings.HasPrefix(key, miner+"-") {
+		return false
+	}
+	if knownKeys == nil {
+		initKeyLook()
+	}
+	return knownKeys[strings.TrimPrefix(key, miner+"-")]
+}
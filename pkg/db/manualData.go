@@ -0,0 +1,305 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// ManualEntry is a value entered by an operator for a request ID
+// that isn't computed from the index sources.
+type ManualEntry struct {
+	RequestID uint64 `json:"requestId"`
+	// Value is the entered value multiplied by the granularity of the request ID.
+	Value *hexutil.Big `json:"value"`
+	// Decimal is the value as it was entered.
+	Decimal string    `json:"decimal"`
+	Expires time.Time `json:"expires"`
+	Created time.Time `json:"created"`
+	// Signer is the address of the operator that signed the entry.
+	Signer    string        `json:"signer,omitempty"`
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// NewManualEntry creates an entry for the decimal value multiplied by the granularity.
+// Values with more decimals than the granularity can hold are rejected instead of being truncated.
+func NewManualEntry(requestID uint64, decimal string, granularity int64, expires time.Time) (*ManualEntry, error) {
+	if granularity < 1 {
+		return nil, errors.Errorf("invalid granularity:%v", granularity)
+	}
+	r, ok := new(big.Rat).SetString(decimal)
+	if !ok {
+		return nil, errors.Errorf("invalid decimal value:%v", decimal)
+	}
+	if r.Sign() < 0 {
+		return nil, errors.Errorf("negative value:%v", decimal)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(granularity))
+	if !r.IsInt() {
+		return nil, errors.Errorf("%v has more decimals than the granularity %v allows", decimal, granularity)
+	}
+	return &ManualEntry{
+		RequestID: requestID,
+		Value:     (*hexutil.Big)(new(big.Int).Set(r.Num())),
+		Decimal:   decimal,
+		Expires:   expires,
+		Created:   time.Now(),
+	}, nil
+}
+
+// Expired reports whether the entry can't be used anymore at the given time.
+func (e *ManualEntry) Expired(at time.Time) bool {
+	return !at.Before(e.Expires)
+}
+
+func (e *ManualEntry) hash() []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("%d:%s:%d", e.RequestID, e.Value.String(), e.Expires.Unix())))
+}
+
+// Sign signs the request ID, value and expiry of the entry with the operator key.
+func (e *ManualEntry) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(e.hash(), key)
+	if err != nil {
+		return errors.Wrap(err, "signing the manual entry")
+	}
+	e.Signature = sig
+	e.Signer = minerAddress(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	return nil
+}
+
+// Verify checks that the signature of the entry matches its signer.
+// When signers are given the entry must be signed by one of them.
+func (e *ManualEntry) Verify(signers []string) error {
+	if len(e.Signature) == 0 {
+		if len(signers) > 0 {
+			return errors.Errorf("manual entry of request id %v isn't signed", e.RequestID)
+		}
+		return nil
+	}
+	pubKey, err := crypto.SigToPub(e.hash(), e.Signature)
+	if err != nil {
+		return errors.Wrapf(err, "manual entry of request id %v has an invalid signature", e.RequestID)
+	}
+	signer := minerAddress(crypto.PubkeyToAddress(*pubKey).Hex())
+	if signer != minerAddress(e.Signer) {
+		return errors.Errorf("manual entry of request id %v isn't signed by %v", e.RequestID, e.Signer)
+	}
+	if len(signers) == 0 {
+		return nil
+	}
+	for _, s := range signers {
+		if minerAddress(s) == signer {
+			return nil
+		}
+	}
+	return errors.Errorf("manual entry of request id %v is signed by %v which isn't an authorized signer", e.RequestID, signer)
+}
+
+// ManualDataAdmin is implemented by the stores that can change the manual entries.
+type ManualDataAdmin interface {
+	// ManualSet signs the entry with the operator key and stores it
+	// replacing any previous entry of the same request ID.
+	ManualSet(e *ManualEntry) error
+	// ManualClear removes the entry of the request ID.
+	ManualClear(requestID uint64) error
+	// ManualData returns all entries sorted by request ID.
+	ManualData() ([]*ManualEntry, error)
+}
+
+// DecodeManualData decodes the entries stored under ManualDataKey.
+func DecodeManualData(data []byte) ([]*ManualEntry, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var entries []*ManualEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "decoding the manual data")
+	}
+	return entries, nil
+}
+
+// StoredManualData returns the manual entries stored in the DB.
+func StoredManualData(localDB DB) ([]*ManualEntry, error) {
+	data, err := localDB.Get(ManualDataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the manual data")
+	}
+	return DecodeManualData(data)
+}
+
+func storeManualData(localDB DB, entries []*ManualEntry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RequestID < entries[j].RequestID
+	})
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "encoding the manual data")
+	}
+	return errors.Wrap(localDB.Put(ManualDataKey, data), "storing the manual data")
+}
+
+// setManualEntry verifies and stores the entry.
+func setManualEntry(localDB DB, e *ManualEntry, signers []string) error {
+	if e.Value == nil {
+		return errors.Errorf("manual entry of request id %v has no value", e.RequestID)
+	}
+	if err := e.Verify(signers); err != nil {
+		return err
+	}
+	if e.Expired(time.Now()) {
+		return errors.Errorf("manual entry of request id %v already expired at %v", e.RequestID, e.Expires)
+	}
+	entries, err := StoredManualData(localDB)
+	if err != nil {
+		return err
+	}
+	updated := []*ManualEntry{e}
+	for _, old := range entries {
+		if old.RequestID != e.RequestID {
+			updated = append(updated, old)
+		}
+	}
+	return storeManualData(localDB, updated)
+}
+
+func clearManualEntry(localDB DB, requestID uint64) error {
+	entries, err := StoredManualData(localDB)
+	if err != nil {
+		return err
+	}
+	var updated []*ManualEntry
+	for _, e := range entries {
+		if e.RequestID != requestID {
+			updated = append(updated, e)
+		}
+	}
+	if len(updated) == len(entries) {
+		return errors.Errorf("no manual entry for request id %v", requestID)
+	}
+	return storeManualData(localDB, updated)
+}
+
+// localManualData changes the manual entries directly in a DB
+// that isn't used by a running miner or data server.
+type localManualData struct {
+	db      DB
+	key     *ecdsa.PrivateKey
+	signers []string
+}
+
+// OpenLocalManualData changes the manual entries directly in the DB.
+// Entries are signed with the key of the current config.
+func OpenLocalManualData(localDB DB) (ManualDataAdmin, error) {
+	key, err := crypto.HexToECDSA(os.Getenv(config.PrivateKeyEnvName))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the private key")
+	}
+	return &localManualData{
+		db:      localDB,
+		key:     key,
+		signers: config.GetConfig().ManualDataSigners,
+	}, nil
+}
+
+func (l *localManualData) ManualSet(e *ManualEntry) error {
+	if err := e.Sign(l.key); err != nil {
+		return err
+	}
+	return setManualEntry(l.db, e, l.signers)
+}
+
+func (l *localManualData) ManualClear(requestID uint64) error {
+	return clearManualEntry(l.db, requestID)
+}
+
+func (l *localManualData) ManualData() ([]*ManualEntry, error) {
+	return StoredManualData(l.db)
+}
+
+// OpenManualDataAdmin connects to the data server running with the current config
+// to change its manual entries with signed requests.
+func OpenManualDataAdmin() (ManualDataAdmin, error) {
+	return openDataServerAdmin()
+}
+
+// updateManualData applies a set or clear request.
+// Must be called with the write lock held.
+func (i *remoteImpl) updateManualData(key string, value []byte) error {
+	switch key {
+	case ManualSetKey:
+		e := &ManualEntry{}
+		if err := json.Unmarshal(value, e); err != nil {
+			return errors.Wrap(err, "decoding the manual entry")
+		}
+		if err := setManualEntry(i.localDB, e, i.manualSigners); err != nil {
+			return err
+		}
+		i.log.Info("Manual value of request id %v set to %v until %v by %v", e.RequestID, e.Decimal, e.Expires, e.Signer)
+	case ManualClearKey:
+		requestID, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid request id:%s", value)
+		}
+		if err := clearManualEntry(i.localDB, requestID); err != nil {
+			return err
+		}
+		i.log.Info("Manual value of request id %v cleared", requestID)
+	}
+	return nil
+}
+
+// canChangeManualData checks whether the miner is the data server itself or an authorized signer.
+func (i *remoteImpl) canChangeManualData(miner string) bool {
+	if miner == i.publicAddress {
+		return true
+	}
+	for _, s := range i.manualSigners {
+		if minerAddress(s) == miner {
+			return true
+		}
+	}
+	return false
+}
+
+// isManualDataAdmin checks whether the key changes the manual entries.
+func isManualDataAdmin(key string) bool {
+	return key == ManualSetKey || key == ManualClearKey
+}
+
+func (i *remoteImpl) ManualSet(e *ManualEntry) error {
+	if err := e.Sign(i.privateKey); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "encoding the manual entry")
+	}
+	_, err = i.Put(ManualSetKey, data)
+	return err
+}
+
+func (i *remoteImpl) ManualClear(requestID uint64) error {
+	_, err := i.Put(ManualClearKey, []byte(strconv.FormatUint(requestID, 10)))
+	return err
+}
+
+func (i *remoteImpl) ManualData() ([]*ManualEntry, error) {
+	data, err := i.Get(ManualDataKey)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeManualData(data)
+}
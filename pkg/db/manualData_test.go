@@ -0,0 +1,82 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestNewManualEntry(t *testing.T) {
+	e, err := NewManualEntry(41, "111.683", 1000, time.Now().Add(time.Hour))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "111683", e.Value.ToInt().String())
+
+	_, err = NewManualEntry(41, "111.6834", 1000, time.Now().Add(time.Hour))
+	testutil.NotOk(t, err, "expected more decimals than the granularity to be rejected")
+	_, err = NewManualEntry(41, "-1", 1000, time.Now().Add(time.Hour))
+	testutil.NotOk(t, err, "expected a negative value to be rejected")
+	_, err = NewManualEntry(41, "abc", 1000, time.Now().Add(time.Hour))
+	testutil.NotOk(t, err, "expected an invalid value to be rejected")
+}
+
+func TestManualEntrySignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	other, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	e, err := NewManualEntry(41, "1.5", 1000, time.Now().Add(time.Hour))
+	testutil.Ok(t, err)
+	testutil.NotOk(t, e.Verify([]string{signer}), "expected an unsigned entry to be rejected")
+	testutil.Ok(t, e.Sign(key))
+	testutil.Ok(t, e.Verify(nil))
+	testutil.Ok(t, e.Verify([]string{signer}))
+	testutil.NotOk(t, e.Verify([]string{crypto.PubkeyToAddress(other.PublicKey).Hex()}), "expected an unauthorized signer to be rejected")
+
+	// Changing the value invalidates the signature.
+	e.Value.ToInt().SetInt64(2000)
+	testutil.NotOk(t, e.Verify([]string{signer}), "expected a changed entry to be rejected")
+}
+
+func TestManualDataAdmin(t *testing.T) {
+	config.OpenTestConfig(t)
+	_, DB, remote := startTestDataServer(t)
+	admin, err := openRemoteDB(nil, []config.RemoteDB{remote})
+	testutil.Ok(t, err)
+
+	e, err := NewManualEntry(41, "111.683", 1000, time.Now().Add(time.Hour))
+	testutil.Ok(t, err)
+	testutil.Ok(t, admin.ManualSet(e))
+	e, err = NewManualEntry(10, "2", 1000, time.Now().Add(time.Hour))
+	testutil.Ok(t, err)
+	testutil.Ok(t, admin.ManualSet(e))
+
+	stored, err := StoredManualData(DB)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(stored))
+	testutil.Equals(t, uint64(10), stored[0].RequestID)
+	testutil.Equals(t, admin.publicAddress, stored[0].Signer)
+
+	entries, err := admin.ManualData()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(entries))
+	testutil.Equals(t, "111683", entries[1].Value.ToInt().String())
+
+	// Entries that already expired are never stored.
+	e, err = NewManualEntry(41, "1", 1000, time.Now().Add(-time.Minute))
+	testutil.Ok(t, err)
+	testutil.NotOk(t, admin.ManualSet(e))
+
+	testutil.Ok(t, admin.ManualClear(41))
+	testutil.NotOk(t, admin.ManualClear(41))
+	entries, err = admin.ManualData()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(entries))
+}
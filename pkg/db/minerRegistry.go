@@ -0,0 +1,302 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// requests are counted per second over this many seconds to get the request rate.
+const _rateWindow = 60
+
+// MinerActivity is what the data server knows about a whitelisted miner.
+// The on-chain details are the latest values saved by the trackers and
+// are left empty until the trackers have saved them.
+type MinerActivity struct {
+	Address string `json:"address"`
+	// LastSeen is the time of the last verified request.
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+	// Requests since the data server started.
+	Requests uint64 `json:"requests"`
+	// RequestRate is the number of requests in the last minute.
+	RequestRate uint64 `json:"requestRate"`
+	// Challenge is the last challenge served to the miner.
+	Challenge string `json:"challenge,omitempty"`
+	// LastSubmission is the time of the last on-chain submission.
+	LastSubmission *time.Time `json:"lastSubmission,omitempty"`
+	// StakeStatus as returned by the contract getStakerInfo, 1 is staked.
+	StakeStatus *int64 `json:"stakeStatus,omitempty"`
+	// Balance is the ETH balance in wei.
+	Balance string `json:"balance,omitempty"`
+}
+
+type minerState struct {
+	lastSeen  time.Time
+	requests  uint64
+	rate      rateCounter
+	challenge []byte
+}
+
+// MinerRegistry keeps track of the miners using the data server.
+// It is also a prometheus collector exposing the activity of every miner.
+type MinerRegistry struct {
+	localDB DB
+	mtx     sync.Mutex
+	miners  map[string]*minerState
+
+	lastSeenDesc       *prometheus.Desc
+	requestsDesc       *prometheus.Desc
+	requestRateDesc    *prometheus.Desc
+	lastSubmissionDesc *prometheus.Desc
+	stakeStatusDesc    *prometheus.Desc
+	balanceDesc        *prometheus.Desc
+}
+
+// NewMinerRegistry creates a registry for the given whitelisted miners.
+func NewMinerRegistry(localDB DB, whitelist []string) *MinerRegistry {
+	r := &MinerRegistry{
+		localDB: localDB,
+		miners:  make(map[string]*minerState),
+
+		lastSeenDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_last_seen_timestamp_seconds",
+			"The time of the last request from the miner",
+			[]string{"address"}, nil,
+		),
+		requestsDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_requests_total",
+			"The total number of requests from the miner",
+			[]string{"address"}, nil,
+		),
+		requestRateDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_requests_last_minute",
+			"The number of requests from the miner in the last minute",
+			[]string{"address"}, nil,
+		),
+		lastSubmissionDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_last_submission_timestamp_seconds",
+			"The time of the last on-chain submission of the miner",
+			[]string{"address"}, nil,
+		),
+		stakeStatusDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_stake_status",
+			"The stake status of the miner, 1 is staked",
+			[]string{"address"}, nil,
+		),
+		balanceDesc: prometheus.NewDesc(
+			"telliot_dataserver_miner_balance",
+			"The ETH balance of the miner in 1e18 eth",
+			[]string{"address"}, nil,
+		),
+	}
+	for _, addr := range whitelist {
+		r.miners[minerAddress(addr)] = &minerState{}
+	}
+	return r
+}
+
+func minerAddress(addr string) string {
+	return strings.ToLower(common.HexToAddress(addr).Hex())
+}
+
+// add starts tracking a newly whitelisted miner.
+func (r *MinerRegistry) add(addr string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.state(minerAddress(addr))
+}
+
+// remove stops tracking a miner that is no longer whitelisted.
+func (r *MinerRegistry) remove(addr string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.miners, minerAddress(addr))
+}
+
+func (r *MinerRegistry) state(addr string) *minerState {
+	s, ok := r.miners[addr]
+	if !ok {
+		s = &minerState{}
+		r.miners[addr] = s
+	}
+	return s
+}
+
+// Seen records a verified request from the miner.
+func (r *MinerRegistry) Seen(addr string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := time.Now()
+	s := r.state(minerAddress(addr))
+	s.lastSeen = now
+	s.requests++
+	s.rate.add(now)
+}
+
+// Served records the challenge sent to the miner, if any.
+func (r *MinerRegistry) Served(addr string, vals map[string][]byte) {
+	challenge, ok := vals[CurrentChallengeKey]
+	if !ok {
+		return
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.state(minerAddress(addr)).challenge = challenge
+}
+
+// Miners returns the activity of all known miners sorted by address.
+func (r *MinerRegistry) Miners() ([]*MinerActivity, error) {
+	r.mtx.Lock()
+	now := time.Now()
+	miners := make([]*MinerActivity, 0, len(r.miners))
+	for addr, s := range r.miners {
+		m := &MinerActivity{
+			Address:     addr,
+			Requests:    s.requests,
+			RequestRate: s.rate.total(now),
+		}
+		if !s.lastSeen.IsZero() {
+			lastSeen := s.lastSeen
+			m.LastSeen = &lastSeen
+		}
+		if len(s.challenge) > 0 {
+			m.Challenge = hexutil.Encode(s.challenge)
+		}
+		miners = append(miners, m)
+	}
+	r.mtx.Unlock()
+
+	sort.Slice(miners, func(i, j int) bool { return miners[i].Address < miners[j].Address })
+	for _, m := range miners {
+		if err := r.readOnChain(m); err != nil {
+			return nil, err
+		}
+	}
+	return miners, nil
+}
+
+// readOnChain adds the details saved by the trackers for the miner.
+func (r *MinerRegistry) readOnChain(m *MinerActivity) error {
+	lastSubmission, err := r.readInt(MinerKey(m.Address, TimeOutKey))
+	if err != nil {
+		return err
+	}
+	if lastSubmission != nil && lastSubmission.Int64() > 0 {
+		t := time.Unix(lastSubmission.Int64(), 0)
+		m.LastSubmission = &t
+	}
+	status, err := r.readInt(MinerKey(m.Address, DisputeStatusKey))
+	if err != nil {
+		return err
+	}
+	if status != nil {
+		s := status.Int64()
+		m.StakeStatus = &s
+	}
+	balance, err := r.readInt(MinerKey(m.Address, BalanceKey))
+	if err != nil {
+		return err
+	}
+	if balance != nil {
+		m.Balance = balance.String()
+	}
+	return nil
+}
+
+func (r *MinerRegistry) readInt(key string) (*big.Int, error) {
+	data, err := r.localDB.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %v", key)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	val, err := hexutil.DecodeBig(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %v", key)
+	}
+	return val, nil
+}
+
+// Describe implements prometheus.Collector.
+func (r *MinerRegistry) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.lastSeenDesc
+	ch <- r.requestsDesc
+	ch <- r.requestRateDesc
+	ch <- r.lastSubmissionDesc
+	ch <- r.stakeStatusDesc
+	ch <- r.balanceDesc
+}
+
+// Collect implements prometheus.Collector.
+func (r *MinerRegistry) Collect(ch chan<- prometheus.Metric) {
+	miners, err := r.Miners()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(r.requestsDesc, err)
+		return
+	}
+	for _, m := range miners {
+		ch <- prometheus.MustNewConstMetric(r.requestsDesc, prometheus.CounterValue, float64(m.Requests), m.Address)
+		ch <- prometheus.MustNewConstMetric(r.requestRateDesc, prometheus.GaugeValue, float64(m.RequestRate), m.Address)
+		if m.LastSeen != nil {
+			ch <- prometheus.MustNewConstMetric(r.lastSeenDesc, prometheus.GaugeValue, float64(m.LastSeen.Unix()), m.Address)
+		}
+		if m.LastSubmission != nil {
+			ch <- prometheus.MustNewConstMetric(r.lastSubmissionDesc, prometheus.GaugeValue, float64(m.LastSubmission.Unix()), m.Address)
+		}
+		if m.StakeStatus != nil {
+			ch <- prometheus.MustNewConstMetric(r.stakeStatusDesc, prometheus.GaugeValue, float64(*m.StakeStatus), m.Address)
+		}
+		if m.Balance != "" {
+			balance, _ := new(big.Float).SetString(m.Balance)
+			eth, _ := balance.Quo(balance, big.NewFloat(1e18)).Float64()
+			ch <- prometheus.MustNewConstMetric(r.balanceDesc, prometheus.GaugeValue, eth, m.Address)
+		}
+	}
+}
+
+// rateCounter counts events per second over the rate window.
+type rateCounter struct {
+	buckets [_rateWindow]uint64
+	last    int64
+}
+
+func (c *rateCounter) add(now time.Time) {
+	c.advance(now)
+	c.buckets[now.Unix()%_rateWindow]++
+}
+
+func (c *rateCounter) total(now time.Time) uint64 {
+	c.advance(now)
+	var total uint64
+	for _, b := range c.buckets {
+		total += b
+	}
+	return total
+}
+
+// advance clears the buckets of the seconds that passed since the last event.
+func (c *rateCounter) advance(now time.Time) {
+	sec := now.Unix()
+	if sec <= c.last {
+		return
+	}
+	if sec-c.last >= _rateWindow {
+		c.buckets = [_rateWindow]uint64{}
+	} else {
+		for s := c.last + 1; s <= sec; s++ {
+			c.buckets[s%_rateWindow] = 0
+		}
+	}
+	c.last = sec
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestMinerRegistry(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8", "0x053b09e98ede40997546e8bb812cd838f18bb146"}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	r := remote.(*remoteImpl)
+	addr := r.publicAddress
+
+	testutil.Ok(t, DB.Put(CurrentChallengeKey, []byte{1, 2, 3}))
+	testutil.Ok(t, DB.Put(addr+"-"+TimeOutKey, []byte(hexutil.EncodeBig(big.NewInt(1600000000)))))
+	testutil.Ok(t, DB.Put(addr+"-"+DisputeStatusKey, []byte(hexutil.EncodeBig(big.NewInt(1)))))
+	testutil.Ok(t, DB.Put(addr+"-"+BalanceKey, []byte(hexutil.EncodeBig(big.NewInt(2e18)))))
+
+	for i := 0; i < 3; i++ {
+		req, err := createRequest([]string{CurrentChallengeKey, RequestIdKey}, nil, addr, r)
+		testutil.Ok(t, err)
+		data, err := encodeRequest(req)
+		testutil.Ok(t, err)
+		resp, err := r.IncomingRequest(data)
+		testutil.Ok(t, err)
+		decoded, err := decodeResponse(resp)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "", decoded.errorMsg)
+	}
+
+	miners, err := r.Registry().Miners()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(miners))
+
+	// The other whitelisted miner hasn't made any requests.
+	idle := miners[0]
+	testutil.Equals(t, "0x053b09e98ede40997546e8bb812cd838f18bb146", idle.Address)
+	testutil.Equals(t, uint64(0), idle.Requests)
+	testutil.Assert(t, idle.LastSeen == nil, "expected an idle miner to not be seen")
+	testutil.Assert(t, idle.StakeStatus == nil, "expected no stake status for an idle miner")
+
+	active := miners[1]
+	testutil.Equals(t, addr, active.Address)
+	testutil.Equals(t, uint64(3), active.Requests)
+	testutil.Equals(t, uint64(3), active.RequestRate)
+	testutil.Assert(t, active.LastSeen != nil && time.Since(*active.LastSeen) < time.Minute, "unexpected last seen:%v", active.LastSeen)
+	testutil.Equals(t, "0x010203", active.Challenge)
+	testutil.Equals(t, time.Unix(1600000000, 0), *active.LastSubmission)
+	testutil.Equals(t, int64(1), *active.StakeStatus)
+	testutil.Equals(t, "2000000000000000000", active.Balance)
+
+	// All metrics for the active miner and only the activity ones for the idle.
+	testutil.Equals(t, 8, promtestutil.CollectAndCount(r.Registry()))
+	expected := `
+# HELP telliot_dataserver_miner_balance The ETH balance of the miner in 1e18 eth
+# TYPE telliot_dataserver_miner_balance gauge
+telliot_dataserver_miner_balance{address="` + addr + `"} 2
+`
+	testutil.Ok(t, promtestutil.CollectAndCompare(r.Registry(), strings.NewReader(expected), "telliot_dataserver_miner_balance"))
+}
+
+func TestRateCounter(t *testing.T) {
+	var c rateCounter
+	start := time.Unix(1600000000, 0)
+	c.add(start)
+	c.add(start)
+	c.add(start.Add(30 * time.Second))
+	testutil.Equals(t, uint64(3), c.total(start.Add(59*time.Second)))
+	testutil.Equals(t, uint64(1), c.total(start.Add(60*time.Second)))
+	testutil.Equals(t, uint64(0), c.total(start.Add(10*time.Minute)))
+}
@@ -3,6 +3,11 @@
 
 package db
 
+import (
+	"context"
+	"io"
+)
+
 // DataServerProxy interface for local interaction/abstraction/testing.
 type DataServerProxy interface {
 	// RequestSigner
@@ -25,3 +30,21 @@ type DataServerProxy interface {
 	// notification that a remote miner has requested data.
 	IncomingRequest(data []byte) ([]byte, error)
 }
+
+// DataStreamer is implemented by data proxies that can push value
+// changes from the data server instead of waiting to be polled.
+type DataStreamer interface {
+	// Stream subscribes to the given keys and keeps their values up to date until
+	// the context is canceled. The returned channel is notified every time any of the values change.
+	// While the stream is down lookups fall back to polling the data server.
+	Stream(ctx context.Context, keys []string) <-chan struct{}
+
+	// notification that a remote miner has subscribed to data.
+	// The values are written to w every time they change.
+	IncomingStream(ctx context.Context, data []byte, w io.Writer) error
+}
+
+// MinerTracker is implemented by data proxies that keep track of the miners they serve.
+type MinerTracker interface {
+	Registry() *MinerRegistry
+}
@@ -6,6 +6,7 @@ package db
 import (
 	"crypto/ecdsa"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -17,20 +18,27 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/fetch"
 	"github.com/tellor-io/telliot/pkg/util"
 )
 
-// how long a signed request is good for before reject it. Semi-protection against replays.
-const _validityThreshold = 2 //seconds
+// how long a signed request is good for before reject it. Requests older or
+// further in the future than this are rejected regardless of their nonce.
+const _validityThreshold = 10 //seconds
+
+// how many nonces to remember per miner. Only needs to cover the requests
+// a single miner makes within the validity threshold.
+const _nonceHistorySize = 1024
 
 var rdbLog *util.Logger
 
 /***************************************************************************************
 ** NOTE: This component is used to proxy data requests from approved miner processes. Miner
-** public addresses are whitelisted and a small history of requests is retained to mitigate
+** public addresses are whitelisted and the nonces of recent requests are retained to mitigate
 ** replay attacks. All incoming requests must be signed by the miner making the request so
-** that the miner's public address can be verified. Best practice is to batch data lookups
-** into single requests to improve performance and security.
+** that the miner's public address can be verified. The signature also covers the protocol
+** version and the data server address so that a request can't be replayed elsewhere.
+** Best practice is to batch data lookups into single requests to improve performance and security.
 **
 ** This component does NOT, repeat NOT, prevent DDoS attacks. Users must
 ** use their own solution to prevent such attacks if operating this code in a publicly
@@ -41,15 +49,43 @@ type remoteImpl struct {
 	privateKey    *ecdsa.PrivateKey
 	publicAddress string
 	localDB       DB
-	whitelist     map[string]bool
-	postURL       string
-	log           *util.Logger
-	wlHistory     map[string]*lru.ARCCache
-	rwLock        sync.RWMutex
+	fetcher       *fetch.Fetcher
+	// client is used for the streams that aren't retried.
+	client *http.Client
+	log    *util.Logger
+
+	// Whitelisted miners, only changed with the write lock held.
+	whitelist       map[string]bool
+	configWhitelist map[string]bool
+	wlHistory       map[string]*lru.ARCCache
+	registry        *MinerRegistry
+	claims          *slotClaims
+	manualSigners   []string
+	nonceLock       sync.Mutex
+	rwLock          sync.RWMutex
+
+	// Data servers in order of preference.
+	servers         []*remoteServer
+	serversLock     sync.Mutex
+	maxDisagreement float64
+
+	// Values pushed by the data server, nil when not streaming.
+	streamLock sync.RWMutex
+	streamVals map[string][]byte
+	streamKeys map[string]bool
 }
 
 // OpenRemoteDB establishes a proxy to a remote data server.
 func OpenRemoteDB(localDB DB) (DataServerProxy, error) {
+	cfg := config.GetConfig()
+	remoteDBs := cfg.Mine.RemoteDBServers
+	if len(remoteDBs) == 0 {
+		remoteDBs = []config.RemoteDB{{Host: cfg.Mine.RemoteDBHost, Port: cfg.Mine.RemoteDBPort}}
+	}
+	return openRemoteDB(localDB, remoteDBs)
+}
+
+func openRemoteDB(localDB DB, remoteDBs []config.RemoteDB) (*remoteImpl, error) {
 	rdbLog = util.NewLogger("db", "RemoteDBProxy")
 
 	cfg := config.GetConfig()
@@ -64,44 +100,73 @@ func OpenRemoteDB(localDB DB) (DataServerProxy, error) {
 	//convert to address
 	fromAddress := common.HexToAddress(_fromAddress)
 
-	whitelist := cfg.ServerWhitelist
-	wlMap := make(map[string]bool)
-	wlLRU := make(map[string]*lru.ARCCache)
-	for _, a := range whitelist {
-		addr := common.HexToAddress(a)
-		asStr := strings.ToLower(addr.Hex())
-		hist, err := lru.NewARC(50)
-		if err != nil {
-			return nil, err
+	// Requests are signed for a specific data server.
+	// Default to our own address for when the miner and the data server share a key.
+	defaultAddress := fromAddress
+	if cfg.Mine.RemoteDBAddress != "" {
+		if !common.IsHexAddress(cfg.Mine.RemoteDBAddress) {
+			return nil, errors.Errorf("invalid remote data server address:%v", cfg.Mine.RemoteDBAddress)
 		}
-		wlLRU[asStr] = hist
-		wlMap[asStr] = true
+		defaultAddress = common.HexToAddress(cfg.Mine.RemoteDBAddress)
+	}
+
+	scheme := "http://"
+	tlsConfig, err := cfg.Mine.RemoteDBTLS.ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "remote DB TLS config")
+	}
+	if tlsConfig != nil {
+		scheme = "https://"
+	}
+	transport, err := fetch.NewTransport(cfg.Fetch, tlsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote DB transport")
+	}
+
+	servers := make([]*remoteServer, 0, len(remoteDBs))
+	for _, r := range remoteDBs {
+		serverAddress := defaultAddress
+		if r.Address != "" {
+			if !common.IsHexAddress(r.Address) {
+				return nil, errors.Errorf("invalid remote data server address:%v", r.Address)
+			}
+			serverAddress = common.HexToAddress(r.Address)
+		}
+		servers = append(servers, &remoteServer{
+			url:     scheme + r.Host + ":" + strconv.Itoa(int(r.Port)),
+			address: strings.ToLower(serverAddress.Hex()),
+			healthy: true,
+		})
 	}
 
-	url := "http://" + cfg.Mine.RemoteDBHost + ":" + strconv.Itoa(int(cfg.Mine.RemoteDBPort))
 	i := &remoteImpl{
-		privateKey:    privateKey,
-		publicAddress: strings.ToLower(fromAddress.Hex()),
-		localDB:       localDB,
-		postURL:       url,
-		whitelist:     wlMap,
-		wlHistory:     wlLRU,
-		log:           util.NewLogger("db", "RemoteDB"),
+		privateKey:      privateKey,
+		publicAddress:   strings.ToLower(fromAddress.Hex()),
+		localDB:         localDB,
+		fetcher:         fetch.New(cfg.Fetch, transport),
+		client:          &http.Client{Transport: transport},
+		servers:         servers,
+		maxDisagreement: cfg.Mine.RemoteDBMaxDisagreement,
+		manualSigners:   cfg.ManualDataSigners,
+		registry:        NewMinerRegistry(localDB, nil),
+		claims:          newSlotClaims(cfg.DataServer.SlotClaims, cfg.DataServer.SlotClaimTimeout.Duration),
+		log:             util.NewLogger("db", "RemoteDB"),
+	}
+	// Only the data server side has a local DB to serve requests from.
+	if localDB != nil {
+		if err := i.loadWhitelist(cfg.ServerWhitelist); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range servers {
+		i.log.Info("Created Remote data proxy connector for %v\n", s)
 	}
-	i.log.Info("Created Remote data proxy connector for %s:%d\n", cfg.Mine.RemoteDBHost, cfg.Mine.RemoteDBPort)
 	return i, nil
 }
 
-// Check whether an incoming storage request key is prefixed by one of our known miner
-// keys. Otherwise, we have to reject the request as invalid or not coming from this
-// codebase.
-func (i *remoteImpl) hasAddressPrefix(key string) bool {
-	for k := range i.whitelist {
-		if strings.HasPrefix(key, k) {
-			return true
-		}
-	}
-	return false
+// Registry returns the miners that used this data server.
+func (i *remoteImpl) Registry() *MinerRegistry {
+	return i.registry
 }
 
 func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
@@ -138,11 +203,38 @@ func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
 
 		//request to write data locally
 		for idx, k := range req.dbKeys {
-			//make sure key is prefixed with address
-			if !i.hasAddressPrefix(k) {
-				return errorResponse("All remote data storage request keys must be prefixed with miner public Ethereum address")
+			// Miners can only write their own keys.
+			if !strings.HasPrefix(k, req.miner+"-") {
+				return errorResponse("All remote data storage request keys must be prefixed with the miner public Ethereum address")
 			}
 			v := req.dbValues[idx]
+			if isWhitelistAdmin(strings.TrimPrefix(k, req.miner+"-")) {
+				if req.miner != i.publicAddress {
+					return errorResponse("The whitelist can only be changed with the data server key")
+				}
+				if err := i.updateWhitelist(strings.TrimPrefix(k, req.miner+"-"), v); err != nil {
+					return errorResponse(err.Error())
+				}
+				continue
+			}
+			if isManualDataAdmin(strings.TrimPrefix(k, req.miner+"-")) {
+				if !i.canChangeManualData(req.miner) {
+					return errorResponse("The manual data can only be changed with the data server key or the key of an authorized signer")
+				}
+				if err := i.updateManualData(strings.TrimPrefix(k, req.miner+"-"), v); err != nil {
+					return errorResponse(err.Error())
+				}
+				continue
+			}
+			if isSlotClaim(k) {
+				if k != req.miner+"-"+SlotClaimKey {
+					return errorResponse("Slot claims must be made by the claiming miner")
+				}
+				v = claimDenied
+				if i.claims.claim(req.miner, req.dbValues[idx], time.Now()) {
+					v = claimGranted
+				}
+			}
 			if err := i.localDB.Put(k, v); err != nil {
 				return errorResponse(err.Error())
 			}
@@ -158,8 +250,19 @@ func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
 
 	outMap := map[string][]byte{}
 	for _, k := range req.dbKeys {
-		if req.dbValues == nil && !isKnownKey(k) {
-			return errorResponse("Invalid lookup key: " + k)
+		if k == WhitelistKey {
+			if req.miner != i.publicAddress {
+				return errorResponse("The whitelist can only be read with the data server key")
+			}
+			bts, err := i.whitelistEntries()
+			if err != nil {
+				return errorResponse(err.Error())
+			}
+			outMap[k] = bts
+			continue
+		}
+		if req.dbValues == nil && !isKnownKey(k) && !isMinerKey(req.miner, k) {
+			return errorResponse((&InvalidKeyError{Key: k}).Error())
 		}
 		rdbLog.Debug("Looking up local DB key: %v", k)
 		bts, err := i.localDB.Get(k)
@@ -173,6 +276,7 @@ func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
 		}
 	}
 
+	i.registry.Served(req.miner, outMap)
 	resp := &responsePayload{dbVals: outMap, errorMsg: ""}
 	return encodeResponse(resp)
 }
@@ -194,29 +298,25 @@ func (i *remoteImpl) Put(key string, value []byte) (map[string][]byte, error) {
 	return i.BatchPut(keys, vals)
 }
 
+// BatchGet returns the streamed values when available
+// and falls back to requesting the rest from the data server.
 func (i *remoteImpl) BatchGet(keys []string) (map[string][]byte, error) {
-	req, err := createRequest(keys, nil, i)
-	if err != nil {
-		return nil, err
+	// Compared lookups always poll all data servers.
+	if i.comparing() {
+		return i.batchGet(keys)
 	}
-	data, err := encodeRequest(req)
-	if err != nil {
-		return nil, err
+	vals, missing := i.streamed(keys)
+	if len(missing) == 0 {
+		return vals, nil
 	}
-	httpReq := &util.HTTPFetchRequest{Method: util.POST, QueryURL: i.postURL, Payload: data, Timeout: time.Duration(10 * time.Second)}
-
-	respData, err := util.HTTPWithRetries(httpReq)
-	if err != nil {
-		return nil, errors.Wrapf(err, "retrieving data after retries")
-	}
-	remResp, err := decodeResponse(respData)
+	remote, err := i.batchGet(missing)
 	if err != nil {
 		return nil, err
 	}
-	if len(remResp.errorMsg) > 0 {
-		return nil, errors.New(remResp.errorMsg)
+	for k, v := range remote {
+		vals[k] = v
 	}
-	return remResp.dbVals, nil
+	return vals, nil
 }
 
 func (i *remoteImpl) BatchPut(keys []string, values [][]byte) (map[string][]byte, error) {
@@ -229,69 +329,52 @@ func (i *remoteImpl) BatchPut(keys []string, values [][]byte) (map[string][]byte
 			dbKeys[idx] = k
 		}
 	}
-	req, err := createRequest(dbKeys, values, i)
-	if err != nil {
-		return nil, err
-	}
-	data, err := encodeRequest(req)
-	if err != nil {
-		return nil, err
-	}
-	httpReq := &util.HTTPFetchRequest{
-		Method:   util.POST,
-		QueryURL: i.postURL,
-		Payload:  data,
-		Timeout:  time.Duration(10 * time.Second),
-	}
-	respData, err := util.HTTPWithRetries(httpReq)
-	if err != nil {
-		//return nil, err
-		return nil, errors.Wrap(err, "put data after retries")
-	}
-	remResp, err := decodeResponse(respData)
-	if err != nil {
-		return nil, err
-	}
-	if len(remResp.errorMsg) > 0 {
-		return nil, errors.New(remResp.errorMsg)
-	}
-	return remResp.dbVals, nil
+	return i.batchPut(dbKeys, values)
 }
 
 func (i *remoteImpl) Sign(hash []byte) ([]byte, error) {
 	return crypto.Sign(hash, i.privateKey)
 }
 
-func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) error {
+func (i *remoteImpl) Verify(hash []byte, serverID string, timestamp int64, nonce []byte, sig []byte) (string, error) {
+	if serverID != i.publicAddress {
+		rdbLog.Warn("Request signed for data server %v instead of %v", serverID, i.publicAddress)
+		return "", &WrongServerError{Address: serverID}
+	}
+
+	now := time.Now()
+	reqTime := time.Unix(timestamp, 0)
+	if now.Sub(reqTime) > _validityThreshold*time.Second || reqTime.Sub(now) > _validityThreshold*time.Second {
+		rdbLog.Warn("Request time %v outside of the validity window (%v)", reqTime, now)
+		return "", errors.Errorf("Request expired")
+	}
+
 	pubKey, err := crypto.SigToPub(hash, sig)
 	if err != nil {
-		return err
+		return "", err
 	}
 	addr := crypto.PubkeyToAddress(*pubKey)
 	ashex := strings.ToLower(addr.Hex())
-	rdbLog.Debug("Verifying signature from %v request against whitelist: %v", ashex, i.whitelist[ashex])
-	if !i.whitelist[ashex] {
+	i.rwLock.RLock()
+	whitelisted, cache := i.whitelist[ashex], i.wlHistory[ashex]
+	i.rwLock.RUnlock()
+	rdbLog.Debug("Verifying signature from %v request against whitelist: %v", ashex, whitelisted)
+	if !whitelisted {
 		rdbLog.Warn("Unauthorized miner detected with address: %v", ashex)
-		return errors.Errorf("Unauthorized")
+		return "", errors.Errorf("Unauthorized")
 	}
 
-	cache := i.wlHistory[ashex]
 	if cache == nil {
-		return errors.Errorf("No history found for address")
+		return "", errors.Errorf("No history found for address")
 	}
-	if cache.Contains(timestamp) {
-		rdbLog.Debug("Miner %v already made request at %v", ashex, timestamp)
-		expr := time.Unix(timestamp+_validityThreshold, 0)
-		now := time.Now()
-		if now.After(expr) {
-			rdbLog.Warn("Request time %v expired (%v)", time.Unix(timestamp, 0), now)
-			return errors.Errorf("Request expired")
-		}
-		rdbLog.Debug("Time of last request: %v compared to %v", expr, now)
-
-	} else {
-		rdbLog.Debug("Never seen miner before: %v at time %v", ashex, timestamp)
+	// Peek and add are not atomic so lock to not let two copies of the same request through.
+	i.nonceLock.Lock()
+	defer i.nonceLock.Unlock()
+	if cache.Contains(string(nonce)) {
+		rdbLog.Warn("Miner %v replayed a request made at %v", ashex, reqTime)
+		return "", errors.Errorf("Request replayed")
 	}
-	cache.Add(timestamp, true)
-	return nil
+	cache.Add(string(nonce), timestamp)
+	i.registry.Seen(ashex)
+	return ashex, nil
 }
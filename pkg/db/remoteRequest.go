@@ -5,6 +5,7 @@ package db
 
 import (
 	"bytes"
+	"crypto/rand"
 	"io"
 	"time"
 
@@ -14,6 +15,28 @@ import (
 	"github.com/tellor-io/telliot/pkg/util"
 )
 
+const (
+	// ProtocolVersion of the miner <-> data server wire protocol.
+	// Requests with any other version are rejected.
+	ProtocolVersion uint16 = 2
+
+	// MaxRequestSize is the maximum size in bytes of an encoded request.
+	MaxRequestSize = 1 << 20
+
+	maxKeys        = 256
+	maxKeyLength   = 256
+	maxValueLength = 64 << 10
+	nonceLength    = 16
+	maxSigLength   = 128
+)
+
+// protocolMagic prefixes every versioned request. Legacy requests start with
+// a big endian unix timestamp so they can never match it.
+var protocolMagic = []byte("TLRP")
+
+// ErrProtocolVersion is returned when a peer speaks a different version of the protocol.
+var ErrProtocolVersion = errors.New("protocol version mismatch")
+
 // RequestSigner handles signing an outgoing request. It's just an abstraction
 // so we can test, etc.
 type RequestSigner interface {
@@ -22,42 +45,61 @@ type RequestSigner interface {
 }
 
 // RequestValidator validates that a miner's signature is valid, that its address
-// is whitelisted, and minizes chances that the requested hash isn't being replayed.
+// is whitelisted, and that the request is neither expired nor replayed.
 type RequestValidator interface {
-	// Verify the given signature was signed by a valid/whitelisted miner address.
-	Verify(hash []byte, timestamp int64, sig []byte) error
+	// Verify the given signature was signed by a valid/whitelisted miner address
+	// for this data server and that the nonce hasn't been used before.
+	// Returns the address of the miner that signed the request.
+	Verify(hash []byte, serverID string, timestamp int64, nonce []byte, sig []byte) (string, error)
 }
 
 // Request payload is encoded and comes from a remote client (miner) that is
 // asking for specific data. Every request has a signature to verify it's
-// coming from a whitelisted client and that it has not been already requested
-// based on its timestamp.
+// coming from a whitelisted client and a random nonce so that it can't be replayed.
 type requestPayload struct {
 
+	// serverID is the public address of the data server the request is for.
+	// Prevents replaying the request against another data server.
+	serverID string
+
+	// nonce is random for every request. Aids in avoiding replay attacks.
+	nonce []byte
+
 	// dbKeys to access the DB.
 	dbKeys []string
 
 	// dbValues to store in the DB.
 	dbValues [][]byte
 
-	// timestamp when the request was sent. Aids in avoiding replay attacks.
+	// timestamp when the request was sent. Requests outside the validity window are rejected.
 	timestamp int64
 
-	// signature of op, dbKey, dbVal, and timestamp.
+	// signature of the version, serverID, nonce, timestamp, dbKeys and dbValues.
 	sig []byte
+
+	// miner is the address that signed the request. Only set for verified incoming requests.
+	miner string
 }
 
 var rrlog *util.Logger = util.NewLogger("db", "RemoteRequest")
 
 // Create an outgoing request for the given keys.
-func createRequest(dbKeys []string, values [][]byte, signer RequestSigner) (*requestPayload, error) {
+func createRequest(dbKeys []string, values [][]byte, serverID string, signer RequestSigner) (*requestPayload, error) {
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generating request nonce")
+	}
+	req := &requestPayload{
+		serverID:  serverID,
+		nonce:     nonce,
+		dbKeys:    dbKeys,
+		dbValues:  values,
+		timestamp: time.Now().Unix(),
+	}
 
-	//rrlog = util.NewLogger("db", "RemoteRequest")
-	t := time.Now().Unix()
 	buf := new(bytes.Buffer)
-	rrlog.Debug("encoding initial keys and timestamp")
-	err := encodeKeysValuesAndTime(buf, dbKeys, values, t)
-	if err != nil {
+	rrlog.Debug("encoding signed request fields")
+	if err := encodeSignedFields(buf, req); err != nil {
 		return nil, err
 	}
 
@@ -74,28 +116,42 @@ func createRequest(dbKeys []string, values [][]byte, signer RequestSigner) (*req
 		log.Error("signature was not generated")
 		return nil, errors.Errorf("Could not generate a signature for  hash: %v", hash)
 	}
-	return &requestPayload{dbKeys: dbKeys, dbValues: values, timestamp: t, sig: sig}, nil
+	req.sig = sig
+	return req, nil
 }
 
-// Since we use keys and time for sig hashing, we have a specific function for
+// Everything but the signature is used for the sig hashing so we have a specific function for
 // encoding just those parts.
-func encodeKeysValuesAndTime(buf *bytes.Buffer, dbKeys []string, values [][]byte, timestamp int64) error {
+func encodeSignedFields(buf *bytes.Buffer, r *requestPayload) error {
+	if err := checkLimits(r.dbKeys, r.dbValues); err != nil {
+		return err
+	}
 
-	rrlog.Debug("Encoding timestamp")
-	if err := encode(buf, timestamp); err != nil {
+	rrlog.Debug("Encoding protocol version")
+	if err := encode(buf, protocolMagic); err != nil {
 		return err
 	}
-	if dbKeys == nil {
-		rrlog.Error("no keys to encode")
-		return errors.Errorf("No keys to encode")
+	if err := encode(buf, ProtocolVersion); err != nil {
+		return err
+	}
+	if err := encodeString(buf, r.serverID); err != nil {
+		return err
+	}
+	if err := encodeBytes(buf, r.nonce); err != nil {
+		return err
+	}
+
+	rrlog.Debug("Encoding timestamp")
+	if err := encode(buf, r.timestamp); err != nil {
+		return err
 	}
 
 	rrlog.Debug("Encoding dbKeys")
-	if err := encode(buf, uint32(len(dbKeys))); err != nil {
+	if err := encode(buf, uint32(len(r.dbKeys))); err != nil {
 		rrlog.Error("problem encoding dbKeys", err.Error())
 		return err
 	}
-	for _, k := range dbKeys {
+	for _, k := range r.dbKeys {
 		rrlog.Debug("Encoding key", k)
 		if err := encodeString(buf, k); err != nil {
 			rrlog.Error("problem encoding key", err.Error())
@@ -103,59 +159,112 @@ func encodeKeysValuesAndTime(buf *bytes.Buffer, dbKeys []string, values [][]byte
 		}
 	}
 
-	if values != nil {
-		if err := encode(buf, uint32(len(values))); err != nil {
-			rrlog.Error("problem encoding values length", err.Error())
+	if err := encode(buf, uint32(len(r.dbValues))); err != nil {
+		rrlog.Error("problem encoding values length", err.Error())
+		return err
+	}
+	for _, v := range r.dbValues {
+		if err := encodeBytes(buf, v); err != nil {
+			rrlog.Error("problem encoding value bytes", err.Error())
 			return err
 		}
-		for _, v := range values {
-			if err := encodeBytes(buf, v); err != nil {
-				rrlog.Error("problem encoding value bytes", err.Error())
-				return err
-			}
-		}
+	}
 
-	} else {
-		if err := encode(buf, uint32(0)); err != nil {
-			rrlog.Error("could not encode zero value", err.Error())
-			return err
+	return nil
+}
+
+// checkLimits rejects requests that the data server would refuse anyway.
+func checkLimits(dbKeys []string, values [][]byte) error {
+	if len(dbKeys) == 0 {
+		rrlog.Error("no keys to encode")
+		return errors.Errorf("No keys to encode")
+	}
+	if len(dbKeys) > maxKeys {
+		return errors.Errorf("too many keys in request: %d, limit: %d", len(dbKeys), maxKeys)
+	}
+	for _, k := range dbKeys {
+		if len(k) > maxKeyLength {
+			return errors.Errorf("key too long: %d, limit: %d", len(k), maxKeyLength)
 		}
 	}
+	if len(values) > maxKeys {
+		return errors.Errorf("too many values in request: %d, limit: %d", len(values), maxKeys)
+	}
+	for _, v := range values {
+		if len(v) > maxValueLength {
+			return errors.Errorf("value too long: %d, limit: %d", len(v), maxValueLength)
+		}
+	}
+	return nil
+}
 
+// decodeVersion checks that the request is using the same protocol version as us.
+func decodeVersion(buf io.Reader) error {
+	magic := make([]byte, len(protocolMagic))
+	if err := decode(buf, &magic); err != nil {
+		return err
+	}
+	if !bytes.Equal(magic, protocolMagic) {
+		return errors.Wrapf(ErrProtocolVersion, "legacy request format, upgrade the miner to protocol version %d", ProtocolVersion)
+	}
+	var version uint16
+	if err := decode(buf, &version); err != nil {
+		return err
+	}
+	if version != ProtocolVersion {
+		return errors.Wrapf(ErrProtocolVersion, "request uses protocol version %d, data server uses %d", version, ProtocolVersion)
+	}
 	return nil
 }
 
-// Decodes just the key and timestamp portions of a buffer.
-func decodeKeysValuesAndTime(buf io.Reader) ([]string, [][]byte, int64, error) {
+// Decodes all signed fields of a request.
+func decodeSignedFields(buf io.Reader) (*requestPayload, error) {
+	if err := decodeVersion(buf); err != nil {
+		return nil, err
+	}
+	serverID, err := decodeString(buf, maxKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := decodeBytes(buf, nonceLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != nonceLength {
+		return nil, errors.Errorf("invalid nonce length: %d", len(nonce))
+	}
 	var time int64
 	if err := decode(buf, &time); err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
-	len := uint32(0)
-	if err := decode(buf, &len); err != nil {
-		return nil, nil, 0, err
+	len, err := decodeLen(buf, maxKeys)
+	if err != nil {
+		return nil, err
 	}
 	dbKeys := make([]string, len)
 	for i := uint32(0); i < len; i++ {
-		s, err := decodeString(buf)
+		s, err := decodeString(buf, maxKeyLength)
 		if err != nil {
-			return nil, nil, 0, err
+			return nil, err
 		}
 		dbKeys[i] = s
 	}
-	len = uint32(0)
-	if err := decode(buf, &len); err != nil {
-		return nil, nil, 0, err
+	len, err = decodeLen(buf, maxKeys)
+	if err != nil {
+		return nil, err
+	}
+	var values [][]byte
+	if len > 0 {
+		values = make([][]byte, len)
 	}
-	values := make([][]byte, len)
 	for i := uint32(0); i < len; i++ {
-		bts, err := decodeBytes(buf)
+		bts, err := decodeBytes(buf, maxValueLength)
 		if err != nil {
-			return nil, nil, 0, err
+			return nil, err
 		}
 		values[i] = bts
 	}
-	return dbKeys, values, time, nil
+	return &requestPayload{serverID: serverID, nonce: nonce, dbKeys: dbKeys, dbValues: values, timestamp: time}, nil
 }
 
 // Encode the given request for transport over the wire.
@@ -169,10 +278,9 @@ func encodeRequest(r *requestPayload) ([]byte, error) {
 		return nil, errors.Errorf("Cannot encode a request without a signature attached")
 	}
 
-	//capture keys and timestamp
-	rrlog.Debug("Encoding keys and time...")
-	if err := encodeKeysValuesAndTime(buf, r.dbKeys, r.dbValues, r.timestamp); err != nil {
-		rrlog.Error("Problem encoding keys and time", err)
+	rrlog.Debug("Encoding signed fields...")
+	if err := encodeSignedFields(buf, r); err != nil {
+		rrlog.Error("Problem encoding signed fields", err)
 		return nil, err
 	}
 
@@ -183,31 +291,43 @@ func encodeRequest(r *requestPayload) ([]byte, error) {
 		return nil, err
 	}
 
+	if buf.Len() > MaxRequestSize {
+		return nil, errors.Errorf("request size %d exceeds the limit of %d", buf.Len(), MaxRequestSize)
+	}
 	return buf.Bytes(), nil
 }
 
-// Decode a request from the given bytes. The signer is used to validate keys
+// Decode a request from the given bytes. The validator is used to validate keys
 // and whitelisted miners.
 func decodeRequest(data []byte, validator RequestValidator) (*requestPayload, error) {
+	if len(data) > MaxRequestSize {
+		return nil, errors.Errorf("request size %d exceeds the limit of %d", len(data), MaxRequestSize)
+	}
 	buf := bytes.NewReader(data)
-	keys, vals, time, err := decodeKeysValuesAndTime(buf)
+	req, err := decodeSignedFields(buf)
 	if err != nil {
 		return nil, err
 	}
-	if len(keys) == 0 {
+	if len(req.dbKeys) == 0 {
 		return nil, errors.Errorf("No dbKeys in incoming request")
 	}
-	sig, err := decodeBytes(buf)
+	sig, err := decodeBytes(buf, maxSigLength)
 	if err != nil {
 		return nil, err
 	}
+	if buf.Len() > 0 {
+		return nil, errors.Errorf("unexpected %d trailing bytes in request", buf.Len())
+	}
 	hBuf := new(bytes.Buffer)
-	if err := encodeKeysValuesAndTime(hBuf, keys, vals, time); err != nil {
+	if err := encodeSignedFields(hBuf, req); err != nil {
 		return nil, err
 	}
 	hash := crypto.Keccak256(hBuf.Bytes())
-	if err := validator.Verify(hash, time, sig); err != nil {
+	miner, err := validator.Verify(hash, req.serverID, req.timestamp, req.nonce, sig)
+	if err != nil {
 		return nil, err
 	}
-	return &requestPayload{dbKeys: keys, dbValues: vals, timestamp: time, sig: sig}, nil
+	req.sig = sig
+	req.miner = miner
+	return req, nil
 }
@@ -0,0 +1,89 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+//go:build go1.18
+// +build go1.18
+
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+// rejectAll is used so that the fuzzers only exercise the decoding.
+type rejectAll struct{}
+
+func (rejectAll) Verify(hash []byte, serverID string, timestamp int64, nonce []byte, sig []byte) (string, error) {
+	return "", errors.New("rejected")
+}
+
+func FuzzDecodeRequest(f *testing.F) {
+	if err := util.SetupLoggingConfig(nil); err != nil {
+		f.Fatal(err)
+	}
+	valid := &requestPayload{
+		serverID:  "0x92f91500e105e3051f3cf94616831b58f6bce1e8",
+		nonce:     make([]byte, nonceLength),
+		dbKeys:    []string{RequestIdKey, DifficultyKey},
+		dbValues:  [][]byte{[]byte("1"), []byte("2")},
+		timestamp: 1,
+		sig:       make([]byte, 65),
+	}
+	data, err := encodeRequest(valid)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Add(append([]byte{}, protocolMagic...))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		req, err := decodeRequest(data, rejectAll{})
+		if err == nil || req != nil {
+			t.Fatal("a request must never pass verification")
+		}
+		// Decoding the signed fields on their own must not panic either
+		// and anything that decodes must encode to the same bytes.
+		req, err = decodeSignedFields(bytes.NewReader(data))
+		if err != nil || len(req.dbKeys) == 0 {
+			return
+		}
+		buf := new(bytes.Buffer)
+		if err := encodeSignedFields(buf, req); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, buf.Bytes()) {
+			t.Fatal("re-encoded request doesn't match the decoded bytes")
+		}
+	})
+}
+
+func FuzzDecodeResponse(f *testing.F) {
+	if err := util.SetupLoggingConfig(nil); err != nil {
+		f.Fatal(err)
+	}
+	data, err := encodeResponse(&responsePayload{dbVals: map[string][]byte{RequestIdKey: []byte("1")}})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	data, err = errorResponse("error")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		resp, err := decodeResponse(data)
+		if err != nil {
+			return
+		}
+		if len(resp.dbVals) > maxKeys {
+			t.Fatalf("decoded %d values, limit is %d", len(resp.dbVals), maxKeys)
+		}
+	})
+}
@@ -5,13 +5,15 @@ package db
 
 import (
 	"bytes"
-
+	"math"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/testutil"
 )
@@ -26,7 +28,7 @@ func TestRemoteRequestCodec(t *testing.T) {
 	testutil.Ok(t, err)
 
 	keys := []string{RequestIdKey, DifficultyKey}
-	req, err := createRequest(keys, nil, remote.(*remoteImpl))
+	req, err := createRequest(keys, nil, remote.(*remoteImpl).servers[0].address, remote.(*remoteImpl))
 	testutil.Ok(t, err)
 
 	testutil.Assert(t, req.timestamp > 0, "Expected a timestamp to get applied to request")
@@ -54,7 +56,7 @@ func TestRequestReplayAttack(t *testing.T) {
 	testutil.Ok(t, err)
 
 	keys := []string{RequestIdKey, DifficultyKey}
-	req, err := createRequest(keys, nil, remote.(*remoteImpl))
+	req, err := createRequest(keys, nil, remote.(*remoteImpl).servers[0].address, remote.(*remoteImpl))
 	testutil.Ok(t, err)
 
 	testutil.Assert(t, req.timestamp > 0, "Expected a timestamp to get applied to request")
@@ -67,12 +69,111 @@ func TestRequestReplayAttack(t *testing.T) {
 	_, err = decodeRequest(encoded, remote.(*remoteImpl))
 	testutil.Ok(t, err)
 
-	// That simulated a call that was decoded. Now we'll wait for timeout on request.
-	time.Sleep((_validityThreshold * 1500) * time.Millisecond)
+	// That simulated a call that was decoded. The same nonce must be rejected right away.
+	_, err = decodeRequest(encoded, remote.(*remoteImpl))
+	testutil.NotOk(t, err, "expected failure when decoding request as a replay")
+
+	// A request with a fresh nonce but an expired timestamp must be rejected as well.
+	req.nonce = []byte("0123456789abcdef")
+	req.timestamp -= 2 * _validityThreshold
+	encoded, err = encodeRequest(resign(t, req, remote.(*remoteImpl)))
+	testutil.Ok(t, err)
+	_, err = decodeRequest(encoded, remote.(*remoteImpl))
+	testutil.NotOk(t, err, "expected failure when decoding request after expiration period")
+}
+
+func TestRequestOtherServer(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+
+	req, err := createRequest([]string{RequestIdKey}, nil, "0x0000000000000000000000000000000000000001", remote.(*remoteImpl))
+	testutil.Ok(t, err)
+	encoded, err := encodeRequest(req)
+	testutil.Ok(t, err)
+
+	_, err = decodeRequest(encoded, remote.(*remoteImpl))
+	testutil.NotOk(t, err, "expected failure when decoding a request signed for another data server")
+
+	// Miners get a typed error to report the misconfigured data server address.
+	respData, err := remote.IncomingRequest(encoded)
+	testutil.Ok(t, err)
+	resp, err := decodeResponse(respData)
+	testutil.Ok(t, err)
+	var wrongServer *WrongServerError
+	testutil.Assert(t, errors.As(resp.err(), &wrongServer), "expected a wrong server error, got:%v", resp.err())
+	testutil.Equals(t, "0x0000000000000000000000000000000000000001", wrongServer.Address)
+}
+
+func TestRequestVersionMismatch(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+
+	// Legacy requests start with the timestamp.
+	legacy := new(bytes.Buffer)
+	testutil.Ok(t, encode(legacy, time.Now().Unix()))
+	testutil.Ok(t, encode(legacy, uint32(1)))
+	testutil.Ok(t, encodeString(legacy, RequestIdKey))
+	testutil.Ok(t, encode(legacy, uint32(0)))
+	testutil.Ok(t, encodeBytes(legacy, make([]byte, 65)))
 
+	data, err := remote.IncomingRequest(legacy.Bytes())
+	testutil.Ok(t, err)
+	resp, err := decodeResponse(data)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(resp.errorMsg, ErrProtocolVersion.Error()), "unexpected error message: %v", resp.errorMsg)
+
+	req, err := createRequest([]string{RequestIdKey}, nil, remote.(*remoteImpl).servers[0].address, remote.(*remoteImpl))
+	testutil.Ok(t, err)
+	encoded, err := encodeRequest(req)
+	testutil.Ok(t, err)
+	// Bump the version right after the magic bytes.
+	encoded[len(protocolMagic)+1]++
 	_, err = decodeRequest(encoded, remote.(*remoteImpl))
+	testutil.Assert(t, errors.Is(err, ErrProtocolVersion), "expected a version mismatch error, got: %v", err)
+}
 
-	testutil.NotOk(t, err, "expected failure when decoding request as a replay after expiration period")
+func TestRequestLimits(t *testing.T) {
+	// A length prefix way above the limits must fail without allocating it.
+	huge := new(bytes.Buffer)
+	testutil.Ok(t, encode(huge, protocolMagic))
+	testutil.Ok(t, encode(huge, ProtocolVersion))
+	testutil.Ok(t, encode(huge, uint32(math.MaxUint32)))
+	_, err := decodeSignedFields(bytes.NewReader(huge.Bytes()))
+	testutil.NotOk(t, err)
+
+	tooMany := make([]string, maxKeys+1)
+	for i := range tooMany {
+		tooMany[i] = RequestIdKey
+	}
+	testutil.NotOk(t, checkLimits(tooMany, nil))
+	testutil.NotOk(t, checkLimits([]string{strings.Repeat("k", maxKeyLength+1)}, nil))
+	testutil.NotOk(t, checkLimits([]string{RequestIdKey}, [][]byte{make([]byte, maxValueLength+1)}))
+
+	resp := new(bytes.Buffer)
+	testutil.Ok(t, encodeString(resp, ""))
+	testutil.Ok(t, encode(resp, uint32(maxKeys+1)))
+	_, err = decodeResponse(resp.Bytes())
+	testutil.NotOk(t, err)
+}
+
+// resign updates the signature of a request after its fields were modified.
+func resign(t *testing.T, req *requestPayload, signer RequestSigner) *requestPayload {
+	buf := new(bytes.Buffer)
+	testutil.Ok(t, encodeSignedFields(buf, req))
+	sig, err := signer.Sign(crypto.Keccak256(buf.Bytes()))
+	testutil.Ok(t, err)
+	req.sig = sig
+	return req
 }
 
 func TestRequestForData(t *testing.T) {
@@ -90,7 +191,7 @@ func TestRequestForData(t *testing.T) {
 	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
 
 	keys := []string{RequestIdKey, DifficultyKey}
-	req, err := createRequest(keys, nil, remote.(*remoteImpl))
+	req, err := createRequest(keys, nil, remote.(*remoteImpl).servers[0].address, remote.(*remoteImpl))
 	testutil.Ok(t, err)
 
 	encoded, err := encodeRequest(req)
@@ -129,7 +230,7 @@ func TestRequestPut(t *testing.T) {
 	dbKey := pubKey + "-" + CurrentChallengeKey
 	vals := make([][]byte, 1)
 	vals[0] = []byte("TEST_CHALLENGE")
-	req, err := createRequest([]string{dbKey}, vals, remote.(*remoteImpl))
+	req, err := createRequest([]string{dbKey}, vals, remote.(*remoteImpl).servers[0].address, remote.(*remoteImpl))
 	testutil.Ok(t, err)
 
 	testutil.Ok(t, DB.Put(dbKey, vals[0]))
@@ -146,3 +247,61 @@ func TestRequestPut(t *testing.T) {
 	testutil.Assert(t, bytes.Equal(data, vals[0]), "DB bytes did not match expected put request data")
 
 }
+
+func TestRequestMinerKeys(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	other := "0x053b09e98ede40997546e8bb812cd838f18bb146"
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8", other}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	r := remote.(*remoteImpl)
+
+	own := MinerKey(r.publicAddress, DisputeStatusKey)
+	testutil.Ok(t, DB.Put(own, []byte("0x1")))
+
+	lookup := func(key string) *responsePayload {
+		req, err := createRequest([]string{key}, nil, r.servers[0].address, r)
+		testutil.Ok(t, err)
+		data, err := encodeRequest(req)
+		testutil.Ok(t, err)
+		out, err := r.IncomingRequest(data)
+		testutil.Ok(t, err)
+		resp, err := decodeResponse(out)
+		testutil.Ok(t, err)
+		return resp
+	}
+
+	resp := lookup(own)
+	testutil.Equals(t, "", resp.errorMsg)
+	testutil.Equals(t, []byte("0x1"), resp.dbVals[own])
+
+	// Miners can't read the values of other miners.
+	for _, key := range []string{
+		MinerKey(other, DisputeStatusKey),
+		r.publicAddress + "-unknown",
+	} {
+		var keyErr *InvalidKeyError
+		err := lookup(key).err()
+		testutil.Assert(t, errors.As(err, &keyErr), "unexpected response:%v", err)
+		testutil.Equals(t, key, keyErr.Key)
+	}
+
+	// Nor write the values of other whitelisted miners.
+	for _, key := range []string{MinerKey(other, TimeOutKey), MinerKey(other, DisputeStatusKey)} {
+		req, err := createRequest([]string{key}, [][]byte{[]byte("0x1")}, r.servers[0].address, r)
+		testutil.Ok(t, err)
+		data, err := encodeRequest(req)
+		testutil.Ok(t, err)
+		out, err := r.IncomingRequest(data)
+		testutil.Ok(t, err)
+		resp, err := decodeResponse(out)
+		testutil.Ok(t, err)
+		testutil.Assert(t, strings.Contains(resp.errorMsg, "prefixed with the miner"), "unexpected response:%v", resp.errorMsg)
+		val, err := DB.Get(key)
+		testutil.Ok(t, err)
+		testutil.Assert(t, val == nil, "expected no value for %v", key)
+	}
+}
@@ -5,8 +5,38 @@ package db
 
 import (
 	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
+// invalidKeyMsg prefixes the error responses for keys the data server doesn't serve.
+// Miners use it to detect older data servers so it must not change.
+const invalidKeyMsg = "Invalid lookup key: "
+
+// InvalidKeyError is returned when a data server rejects a key it doesn't serve,
+// e.g. a key added in a newer version.
+type InvalidKeyError struct {
+	Key string
+}
+
+func (e *InvalidKeyError) Error() string {
+	return invalidKeyMsg + e.Key
+}
+
+// wrongServerMsg prefixes the error responses for requests signed for another data server.
+const wrongServerMsg = "request signed for another data server: "
+
+// WrongServerError is returned when a data server rejects a request signed for another
+// data server, usually because the miner isn't configured with the address of the data server.
+type WrongServerError struct {
+	Address string
+}
+
+func (e *WrongServerError) Error() string {
+	return wrongServerMsg + e.Address
+}
+
 // responsePayload from remote request contains either an error message
 // or a map of requested keys and their values.
 type responsePayload struct {
@@ -14,6 +44,20 @@ type responsePayload struct {
 	dbVals   map[string][]byte
 }
 
+// err returns the error of an error response.
+func (r *responsePayload) err() error {
+	if len(r.errorMsg) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(r.errorMsg, invalidKeyMsg) {
+		return &InvalidKeyError{Key: strings.TrimPrefix(r.errorMsg, invalidKeyMsg)}
+	}
+	if strings.HasPrefix(r.errorMsg, wrongServerMsg) {
+		return &WrongServerError{Address: strings.TrimPrefix(r.errorMsg, wrongServerMsg)}
+	}
+	return errors.New(r.errorMsg)
+}
+
 // Encode the given request for transport over the wire.
 func encodeResponse(r *responsePayload) ([]byte, error) {
 	buf := new(bytes.Buffer)
@@ -42,25 +86,25 @@ func encodeResponse(r *responsePayload) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// Decode a request from the given bytes. The signer is used to validate keys
-// and whitelisted miners.
+// Decode a response from the given bytes. All lengths are bounded by the
+// same limits that apply to requests.
 func decodeResponse(data []byte) (*responsePayload, error) {
 	buf := bytes.NewReader(data)
-	errMsg, err := decodeString(buf)
+	errMsg, err := decodeString(buf, maxValueLength)
 	if err != nil {
 		return nil, err
 	}
-	mapLen := uint32(0)
-	if err = decode(buf, &mapLen); err != nil {
+	mapLen, err := decodeLen(buf, maxKeys)
+	if err != nil {
 		return nil, err
 	}
 	dbVals := make(map[string][]byte)
 	for i := uint32(0); i < mapLen; i++ {
-		k, err := decodeString(buf)
+		k, err := decodeString(buf, maxKeyLength)
 		if err != nil {
 			return nil, err
 		}
-		bts, err := decodeBytes(buf)
+		bts, err := decodeBytes(buf, maxValueLength)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,272 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/fetch"
+)
+
+const (
+	// how long to wait before checking a failed data server again.
+	_healthCheckInterval = 30 * time.Second
+	// how long to retry a single data server.
+	_requestTimeout = 10 * time.Second
+	// how long to retry a single data server when another one can take over.
+	_failoverTimeout = 3 * time.Second
+)
+
+// ErrDataServersDisagree is returned when the data servers report different
+// challenges or values that differ more than the configured threshold.
+var ErrDataServersDisagree = errors.New("data servers disagree")
+
+// consensusKeys must be the same on all data servers.
+var consensusKeys = map[string]bool{
+	CurrentChallengeKey: true,
+	RequestIdKey:        true,
+	RequestIdKey0:       true,
+	RequestIdKey1:       true,
+	RequestIdKey2:       true,
+	RequestIdKey3:       true,
+	RequestIdKey4:       true,
+}
+
+// remoteServer is a single data server used by a remote miner.
+type remoteServer struct {
+	url     string
+	address string
+	healthy bool
+	checkAt time.Time
+}
+
+func (s *remoteServer) String() string {
+	return s.url
+}
+
+// comparing reports whether lookups are compared between the data servers.
+func (i *remoteImpl) comparing() bool {
+	return i.maxDisagreement > 0 && len(i.servers) > 1
+}
+
+// healthyServers returns the healthy data servers in order of preference.
+// Failed servers are checked in the background and used again once they respond.
+// When all servers have failed all of them are tried rather than failing straight away.
+func (i *remoteImpl) healthyServers() []*remoteServer {
+	i.serversLock.Lock()
+	defer i.serversLock.Unlock()
+
+	now := time.Now()
+	var healthy []*remoteServer
+	for _, s := range i.servers {
+		if s.healthy {
+			healthy = append(healthy, s)
+			continue
+		}
+		if len(i.servers) > 1 && now.After(s.checkAt) {
+			s.checkAt = now.Add(_healthCheckInterval)
+			go i.checkHealth(s)
+		}
+	}
+	if len(healthy) == 0 {
+		return append([]*remoteServer(nil), i.servers...)
+	}
+	return healthy
+}
+
+// checkHealth makes a single lookup to see whether a failed data server is back.
+func (i *remoteImpl) checkHealth(s *remoteServer) {
+	_, err := i.request(s, []string{RequestIdKey}, nil)
+	i.setHealth(s, err)
+}
+
+func (i *remoteImpl) setHealth(s *remoteServer, err error) {
+	i.serversLock.Lock()
+	defer i.serversLock.Unlock()
+	if err == nil {
+		if !s.healthy {
+			i.log.Info("Data server %v is healthy again", s)
+		}
+		s.healthy = true
+		return
+	}
+	if s.healthy && len(i.servers) > 1 {
+		i.log.Warn("Data server %v failed, using the next healthy server: %v", s, err)
+	}
+	s.healthy = false
+	s.checkAt = time.Now().Add(_healthCheckInterval)
+}
+
+// request sends a signed request to the given data server. Only a failure to talk to the
+// server is returned as an error, error responses are left to the caller.
+func (i *remoteImpl) request(s *remoteServer, keys []string, values [][]byte) (*responsePayload, error) {
+	req, err := createRequest(keys, values, s.address, i)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	timeout := _requestTimeout
+	if len(i.servers) > 1 {
+		timeout = _failoverTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	respData, err := i.fetcher.Do(ctx, &fetch.Request{
+		Method: http.MethodPost,
+		URL:    s.url,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   data,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "data server %v", s)
+	}
+	return decodeResponse(respData)
+}
+
+// batchGet looks up the keys on the first data server that responds
+// or on all of them when comparing.
+func (i *remoteImpl) batchGet(keys []string) (map[string][]byte, error) {
+	servers := i.healthyServers()
+	if i.comparing() && len(servers) > 1 {
+		return i.compareGet(servers, keys)
+	}
+	var lastErr error
+	for _, s := range servers {
+		remResp, err := i.request(s, keys, nil)
+		i.setHealth(s, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := remResp.err(); err != nil {
+			return nil, err
+		}
+		return remResp.dbVals, nil
+	}
+	return nil, errors.Wrapf(lastErr, "retrieving data after retries")
+}
+
+// compareGet looks up the keys on all given data servers and returns the
+// values of the most preferred one when none of them disagree.
+func (i *remoteImpl) compareGet(servers []*remoteServer, keys []string) (map[string][]byte, error) {
+	resps := make([]*responsePayload, len(servers))
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for idx, s := range servers {
+		wg.Add(1)
+		go func(idx int, s *remoteServer) {
+			defer wg.Done()
+			resps[idx], errs[idx] = i.request(s, keys, nil)
+			i.setHealth(s, errs[idx])
+		}(idx, s)
+	}
+	wg.Wait()
+
+	var first *remoteServer
+	var vals map[string][]byte
+	var lastErr error
+	for idx, s := range servers {
+		if errs[idx] != nil {
+			lastErr = errs[idx]
+			continue
+		}
+		if err := resps[idx].err(); err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first, vals = s, resps[idx].dbVals
+			continue
+		}
+		if err := compareValues(vals, resps[idx].dbVals, i.maxDisagreement); err != nil {
+			i.log.Error("Data servers %v and %v disagree: %v", first, s, err)
+			return nil, errors.Wrapf(ErrDataServersDisagree, "%v and %v: %v", first, s, err)
+		}
+	}
+	if first == nil {
+		return nil, errors.Wrapf(lastErr, "retrieving data after retries")
+	}
+	return vals, nil
+}
+
+// batchPut writes to all healthy data servers and succeeds when at least one of them accepts the write.
+func (i *remoteImpl) batchPut(keys []string, values [][]byte) (map[string][]byte, error) {
+	var out map[string][]byte
+	var lastErr error
+	for _, s := range i.healthyServers() {
+		remResp, err := i.request(s, keys, values)
+		i.setHealth(s, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(remResp.errorMsg) > 0 {
+			lastErr = errors.Errorf("data server %v: %v", s, remResp.errorMsg)
+			i.log.Error("Put rejected by data server %v: %v", s, remResp.errorMsg)
+			continue
+		}
+		if out == nil {
+			out = remResp.dbVals
+		}
+	}
+	if out == nil {
+		return nil, errors.Wrap(lastErr, "put data after retries")
+	}
+	return out, nil
+}
+
+// compareValues checks the keys that both data servers returned.
+// Consensus keys must be equal and values can't differ more than the given relative amount.
+func compareValues(a, b map[string][]byte, maxDisagreement float64) error {
+	for k, av := range a {
+		bv, ok := b[k]
+		if !ok {
+			continue
+		}
+		if consensusKeys[k] {
+			if !bytes.Equal(av, bv) {
+				return errors.Errorf("different %v", k)
+			}
+			continue
+		}
+		if !strings.HasPrefix(k, QueriedValuePrefix) {
+			continue
+		}
+		aInt, err := hexutil.DecodeBig(string(av))
+		if err != nil {
+			return errors.Wrapf(err, "decoding %v", k)
+		}
+		bInt, err := hexutil.DecodeBig(string(bv))
+		if err != nil {
+			return errors.Wrapf(err, "decoding %v", k)
+		}
+		if diff := relativeDiff(aInt, bInt); diff > maxDisagreement {
+			return errors.Errorf("%v differs by %.4f, max allowed:%v", k, diff, maxDisagreement)
+		}
+	}
+	return nil
+}
+
+func relativeDiff(a, b *big.Int) float64 {
+	if a.Cmp(b) == 0 {
+		return 0
+	}
+	max := new(big.Int).Abs(a)
+	if abs := new(big.Int).Abs(b); abs.Cmp(max) > 0 {
+		max = abs
+	}
+	diff := new(big.Float).SetInt(new(big.Int).Abs(new(big.Int).Sub(a, b)))
+	rel, _ := diff.Quo(diff, new(big.Float).SetInt(max)).Float64()
+	return rel
+}
@@ -0,0 +1,141 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// startTestDataServer serves the given DB the same way the rest package does.
+func startTestDataServer(t *testing.T) (*httptest.Server, DB, config.RemoteDB) {
+	DB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadAll(req.Body)
+		testutil.Ok(t, err)
+		out, err := proxy.IncomingRequest(data)
+		testutil.Ok(t, err)
+		_, err = w.Write(out)
+		testutil.Ok(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	return srv, DB, config.RemoteDB{Host: host, Port: uint(p)}
+}
+
+func TestRemoteFailover(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	srv1, DB1, remote1 := startTestDataServer(t)
+	_, DB2, remote2 := startTestDataServer(t)
+	testutil.Ok(t, DB1.Put(RequestIdKey, []byte("1")))
+	testutil.Ok(t, DB2.Put(RequestIdKey, []byte("2")))
+
+	cfg.Mine.RemoteDBServers = []config.RemoteDB{remote1, remote2}
+	localDB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := OpenRemoteDB(localDB)
+	testutil.Ok(t, err)
+	r := proxy.(*remoteImpl)
+
+	val, err := proxy.Get(RequestIdKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte("1"), val)
+
+	// Writes go to all healthy data servers.
+	_, err = proxy.Put(TimeOutKey, []byte("10"))
+	testutil.Ok(t, err)
+	for _, db := range []DB{DB1, DB2} {
+		val, err := db.Get(r.publicAddress + "-" + TimeOutKey)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []byte("10"), val)
+	}
+
+	srv1.Close()
+	val, err = proxy.Get(RequestIdKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte("2"), val)
+	testutil.Assert(t, !r.servers[0].healthy, "expected the closed data server to be unhealthy")
+	testutil.Equals(t, []*remoteServer{r.servers[1]}, r.healthyServers())
+}
+
+func TestRemoteDisagreement(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	_, DB1, remote1 := startTestDataServer(t)
+	_, DB2, remote2 := startTestDataServer(t)
+	cfg.Mine.RemoteDBServers = []config.RemoteDB{remote1, remote2}
+	cfg.Mine.RemoteDBMaxDisagreement = 0.01
+
+	localDB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := OpenRemoteDB(localDB)
+	testutil.Ok(t, err)
+
+	valKey := QueriedValuePrefix + "1"
+	keys := []string{CurrentChallengeKey, valKey}
+	put := func(db DB, challenge string, val int64) {
+		testutil.Ok(t, db.Put(CurrentChallengeKey, []byte(challenge)))
+		testutil.Ok(t, db.Put(valKey, []byte(hexutil.EncodeBig(big.NewInt(val)))))
+	}
+
+	put(DB1, "challenge", 1000)
+	put(DB2, "challenge", 1005)
+	vals, err := proxy.BatchGet(keys)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte(hexutil.EncodeBig(big.NewInt(1000))), vals[valKey])
+
+	put(DB2, "challenge", 1100)
+	_, err = proxy.BatchGet(keys)
+	testutil.Assert(t, errors.Is(err, ErrDataServersDisagree), "expected values to disagree:%v", err)
+
+	put(DB2, "other challenge", 1000)
+	_, err = proxy.BatchGet(keys)
+	testutil.Assert(t, errors.Is(err, ErrDataServersDisagree), "expected challenges to disagree:%v", err)
+}
+
+func TestSlotClaims(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	cfg.DataServer.SlotClaims = 1
+
+	_, _, remote := startTestDataServer(t)
+	cfg.Mine.RemoteDBServers = []config.RemoteDB{remote}
+	localDB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := OpenRemoteDB(localDB)
+	testutil.Ok(t, err)
+	claimer := proxy.(SlotClaimer)
+
+	granted, err := claimer.ClaimSlot([]byte("challenge"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, granted, "expected the first claim to be granted")
+	// Claiming again keeps the claim.
+	granted, err = claimer.ClaimSlot([]byte("challenge"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, granted, "expected the claim to be kept")
+
+	// Claims can only be made for the requesting miner.
+	_, err = proxy.Put("0x053b09e98ede40997546e8bb812cd838f18bb146-"+SlotClaimKey, []byte("challenge"))
+	testutil.NotOk(t, err)
+}
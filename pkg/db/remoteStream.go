@@ -0,0 +1,294 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StreamPath is where remote miners subscribe to the data server updates.
+const StreamPath = "/stream"
+
+const (
+	// how often the data server checks the subscribed keys for changes.
+	_streamCheckInterval = time.Second
+	// how often an unchanged stream sends a heartbeat so miners can detect dead connections.
+	_streamHeartbeat = 15 * time.Second
+	// a miner drops the stream when nothing is received for this long.
+	_streamIdleTimeout = 3 * _streamHeartbeat
+	// streams are closed after this long so that miners re-authenticate.
+	_streamMaxDuration = 10 * time.Minute
+	// backoff between reconnects while the stream is down.
+	_streamMinBackoff = time.Second
+	_streamMaxBackoff = time.Minute
+)
+
+/***************************************************************************************
+** NOTE: A stream is a long lived chunked HTTP response. The miner subscribes once with
+** a signed request for the keys it needs and the data server writes a frame with
+** all their values every time any of them change. Every frame is length prefixed
+** and a zero length frame is a heartbeat. Polling is used whenever the stream is down.
+***************************************************************************************/
+
+// Stream keeps the given keys up to date until the context is canceled.
+func (i *remoteImpl) Stream(ctx context.Context, keys []string) <-chan struct{} {
+	updates := make(chan struct{}, 1)
+	go func() {
+		wait := _streamMinBackoff
+		for {
+			start := time.Now()
+			err := i.stream(ctx, keys, updates)
+			i.setStreamed(nil, nil)
+			if ctx.Err() != nil {
+				return
+			}
+			// Older data servers reject the keys they don't serve, these are polled instead.
+			var keyErr *InvalidKeyError
+			if errors.As(err, &keyErr) {
+				if rest, ok := withoutKey(keys, keyErr.Key); ok {
+					i.log.Warn("data server doesn't stream %v, polling it instead", keyErr.Key)
+					keys = rest
+					continue
+				}
+			}
+			if err == nil {
+				// The data server closed the stream so just subscribe again.
+				wait = _streamMinBackoff
+				continue
+			}
+			if time.Since(start) > _streamMaxBackoff {
+				wait = _streamMinBackoff
+			}
+			i.log.Warn("data server stream closed, polling until reconnected in %v: %v", wait, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			wait *= 2
+			if wait > _streamMaxBackoff {
+				wait = _streamMaxBackoff
+			}
+		}
+	}()
+	return updates
+}
+
+// stream runs a single subscription until the data server closes it or an error occurs.
+func (i *remoteImpl) stream(ctx context.Context, keys []string, updates chan struct{}) error {
+	s := i.healthyServers()[0]
+	req, err := createRequest(keys, nil, s.address, i)
+	if err != nil {
+		return err
+	}
+	data, err := encodeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	httpReq, err := http.NewRequest(http.MethodPost, s.url+StreamPath, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "creating the stream request")
+	}
+	resp, err := i.client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() == nil {
+			i.setHealth(s, err)
+		}
+		return errors.Wrapf(err, "subscribing to data server %v", s)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("data server stream status:%v", resp.Status)
+	}
+	i.log.Info("Subscribed to data server %v for %d keys", s, len(keys))
+
+	// Drop the stream when the data server goes silent.
+	idle := time.AfterFunc(_streamIdleTimeout, cancel)
+	defer idle.Stop()
+
+	keySet := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		keySet[k] = true
+	}
+	for {
+		frame, err := readFrame(resp.Body)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading the stream")
+		}
+		idle.Reset(_streamIdleTimeout)
+		if len(frame) == 0 {
+			continue
+		}
+		remResp, err := decodeResponse(frame)
+		if err != nil {
+			return err
+		}
+		if err := remResp.err(); err != nil {
+			return err
+		}
+		i.setStreamed(keySet, remResp.dbVals)
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// withoutKey returns the keys except the given one and whether it was removed.
+func withoutKey(keys []string, key string) ([]string, bool) {
+	var rest []string
+	for _, k := range keys {
+		if k != key {
+			rest = append(rest, k)
+		}
+	}
+	return rest, len(rest) < len(keys)
+}
+
+func (i *remoteImpl) setStreamed(keys map[string]bool, vals map[string][]byte) {
+	i.streamLock.Lock()
+	defer i.streamLock.Unlock()
+	i.streamKeys = keys
+	i.streamVals = vals
+}
+
+// streamed returns the streamed values for the given keys
+// and the keys that aren't covered by the stream.
+func (i *remoteImpl) streamed(keys []string) (map[string][]byte, []string) {
+	i.streamLock.RLock()
+	defer i.streamLock.RUnlock()
+	vals := make(map[string][]byte)
+	var missing []string
+	for _, k := range keys {
+		if !i.streamKeys[k] {
+			missing = append(missing, k)
+			continue
+		}
+		if v, ok := i.streamVals[k]; ok {
+			vals[k] = v
+		}
+	}
+	return vals, missing
+}
+
+// IncomingStream serves a subscription from a remote miner until the context is canceled.
+func (i *remoteImpl) IncomingStream(ctx context.Context, data []byte, w io.Writer) error {
+	req, err := decodeRequest(data, i)
+	if err != nil {
+		return i.streamError(w, err)
+	}
+	if len(req.dbValues) > 0 {
+		return i.streamError(w, errors.New("streams are read only"))
+	}
+	for _, k := range req.dbKeys {
+		if !isKnownKey(k) && !isMinerKey(req.miner, k) {
+			return i.streamError(w, &InvalidKeyError{Key: k})
+		}
+	}
+	i.log.Info("Streaming %d keys to a remote miner", len(req.dbKeys))
+
+	ctx, cancel := context.WithTimeout(ctx, _streamMaxDuration)
+	defer cancel()
+	check := time.NewTicker(_streamCheckInterval)
+	defer check.Stop()
+	heartbeat := time.NewTicker(_streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var last map[string][]byte
+	for {
+		vals, err := i.localValues(req.dbKeys)
+		if err != nil {
+			return i.streamError(w, err)
+		}
+		if last == nil || !equalValues(last, vals) {
+			out, err := encodeResponse(&responsePayload{dbVals: vals})
+			if err != nil {
+				return err
+			}
+			if err := writeFrame(w, out); err != nil {
+				return err
+			}
+			i.registry.Served(req.miner, vals)
+			last = vals
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-check.C:
+		case <-heartbeat.C:
+			if err := writeFrame(w, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (i *remoteImpl) localValues(keys []string) (map[string][]byte, error) {
+	i.rwLock.RLock()
+	defer i.rwLock.RUnlock()
+	vals := make(map[string][]byte)
+	for _, k := range keys {
+		bts, err := i.localDB.Get(k)
+		if err != nil {
+			return nil, err
+		}
+		if bts != nil {
+			vals[k] = bts
+		}
+	}
+	return vals, nil
+}
+
+// streamError sends the error to the miner before closing the stream.
+func (i *remoteImpl) streamError(w io.Writer, err error) error {
+	rdbLog.Error("Problem with incoming stream: %v", err)
+	out, e := errorResponse(err.Error())
+	if e != nil {
+		return e
+	}
+	if e := writeFrame(w, out); e != nil {
+		return e
+	}
+	return err
+}
+
+func equalValues(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || !bytes.Equal(v, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFrame writes the length prefixed data with a single write
+// so that a flushing writer sends every frame as soon as it is written.
+func writeFrame(w io.Writer, data []byte) error {
+	buf := new(bytes.Buffer)
+	if err := encodeBytes(buf, data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	return decodeBytes(r, MaxRequestSize)
+}
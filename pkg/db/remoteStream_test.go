@@ -0,0 +1,82 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestIncomingStreamRejects(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	r := remote.(*remoteImpl)
+
+	for name, tc := range map[string]struct {
+		keys   []string
+		values [][]byte
+		err    string
+	}{
+		"unknown key": {keys: []string{"unknown"}, err: "Invalid lookup key"},
+		"write":       {keys: []string{r.publicAddress + "-" + TimeOutKey}, values: [][]byte{[]byte("1")}, err: "read only"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := createRequest(tc.keys, tc.values, r.servers[0].address, r)
+			testutil.Ok(t, err)
+			data, err := encodeRequest(req)
+			testutil.Ok(t, err)
+
+			buf := new(bytes.Buffer)
+			err = r.IncomingStream(context.Background(), data, buf)
+			testutil.NotOk(t, err)
+			testutil.Assert(t, strings.Contains(err.Error(), tc.err), "unexpected error:%v", err)
+
+			frame, err := readFrame(buf)
+			testutil.Ok(t, err)
+			resp, err := decodeResponse(frame)
+			testutil.Ok(t, err)
+			testutil.Assert(t, strings.Contains(resp.errorMsg, tc.err), "unexpected error response:%v", resp.errorMsg)
+		})
+	}
+}
+
+func TestIncomingStreamEnds(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	testutil.Ok(t, DB.Put(RequestIdKey, []byte("1")))
+	remote, err := OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	r := remote.(*remoteImpl)
+
+	req, err := createRequest([]string{RequestIdKey}, nil, r.servers[0].address, r)
+	testutil.Ok(t, err)
+	data, err := encodeRequest(req)
+	testutil.Ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf := new(bytes.Buffer)
+	testutil.Ok(t, r.IncomingStream(ctx, data, buf))
+
+	// The current values are always sent first.
+	frame, err := readFrame(buf)
+	testutil.Ok(t, err)
+	resp, err := decodeResponse(frame)
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string][]byte{RequestIdKey: []byte("1")}, resp.dbVals)
+	testutil.Equals(t, 0, buf.Len())
+}
@@ -0,0 +1,100 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+var (
+	claimGranted = []byte(hexutil.EncodeUint64(1))
+	claimDenied  = []byte(hexutil.EncodeUint64(0))
+)
+
+// SlotClaimer is implemented by data proxies that coordinate
+// the submissions of the miners sharing a data server.
+type SlotClaimer interface {
+	// ClaimSlot reports whether the miner is allowed to submit a solution for the challenge.
+	ClaimSlot(challenge []byte) (bool, error)
+}
+
+// slotClaims grants at most max claims per challenge.
+// Every claim is released after the timeout.
+type slotClaims struct {
+	max     int
+	timeout time.Duration
+	mtx     sync.Mutex
+	// challenge -> miner -> claim expiry
+	claims map[string]map[string]time.Time
+}
+
+func newSlotClaims(max int, timeout time.Duration) *slotClaims {
+	return &slotClaims{
+		max:     max,
+		timeout: timeout,
+		claims:  make(map[string]map[string]time.Time),
+	}
+}
+
+// claim reports whether the miner holds one of the claims for the challenge.
+// A miner that already holds a claim keeps it until it expires.
+func (c *slotClaims) claim(miner string, challenge []byte, now time.Time) bool {
+	if c.max <= 0 {
+		return true
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for ch, miners := range c.claims {
+		for m, expiry := range miners {
+			if !now.Before(expiry) {
+				delete(miners, m)
+			}
+		}
+		if len(miners) == 0 {
+			delete(c.claims, ch)
+		}
+	}
+
+	miners, ok := c.claims[string(challenge)]
+	if !ok {
+		miners = make(map[string]time.Time)
+		c.claims[string(challenge)] = miners
+	}
+	if _, ok := miners[miner]; ok {
+		return true
+	}
+	if len(miners) >= c.max {
+		return false
+	}
+	miners[miner] = now.Add(c.timeout)
+	return true
+}
+
+// ClaimSlot asks the data server for one of the claims on the challenge.
+func (i *remoteImpl) ClaimSlot(challenge []byte) (bool, error) {
+	resp, err := i.Put(SlotClaimKey, challenge)
+	if err != nil {
+		return false, errors.Wrap(err, "claiming a slot")
+	}
+	val := resp[i.publicAddress+"-"+SlotClaimKey]
+	switch {
+	case bytes.Equal(val, claimGranted):
+		return true, nil
+	case bytes.Equal(val, claimDenied):
+		return false, nil
+	}
+	return false, errors.Errorf("unexpected slot claim response:%v", string(val))
+}
+
+// isSlotClaim reports whether the key is a slot claim of any miner.
+func isSlotClaim(key string) bool {
+	return strings.HasSuffix(key, "-"+SlotClaimKey)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestSlotClaimLimit(t *testing.T) {
+	c := newSlotClaims(2, time.Minute)
+	now := time.Unix(1600000000, 0)
+	challenge := []byte("challenge")
+
+	testutil.Assert(t, c.claim("miner1", challenge, now), "expected a claim for miner1")
+	testutil.Assert(t, c.claim("miner2", challenge, now), "expected a claim for miner2")
+	testutil.Assert(t, !c.claim("miner3", challenge, now), "expected no claims left for miner3")
+	testutil.Assert(t, c.claim("miner1", challenge, now.Add(30*time.Second)), "expected miner1 to keep its claim")
+	testutil.Assert(t, c.claim("miner3", []byte("other challenge"), now), "expected claims for another challenge")
+
+	// Claims are released after the timeout.
+	testutil.Assert(t, c.claim("miner3", challenge, now.Add(time.Minute)), "expected a released claim for miner3")
+	testutil.Equals(t, 1, len(c.claims[string(challenge)]))
+
+	unlimited := newSlotClaims(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		testutil.Assert(t, unlimited.claim("miner", challenge, now), "expected unlimited claims")
+	}
+}
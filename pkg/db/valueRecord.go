@@ -0,0 +1,56 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// ValueRecord is a value computed for a request ID with the details
+// needed to decide whether it is still good enough to submit.
+type ValueRecord struct {
+	Value *hexutil.Big `json:"value"`
+	// Time when the value was computed.
+	Time time.Time `json:"time"`
+	// Confidence of the value between 0 and 1.
+	Confidence float64 `json:"confidence"`
+	// Sources is the number of APIs that contributed to the value.
+	Sources int `json:"sources"`
+	// Dispersion is the largest relative deviation of a source from the median of its symbol.
+	Dispersion float64 `json:"dispersion"`
+	// SourceValues is the latest value of every API used for the value
+	// keyed by the symbol and the API name, so that remote miners can log them.
+	SourceValues map[string]float64 `json:"sourceValues,omitempty"`
+}
+
+// ValueRecordKey returns the key of the value record of the request ID.
+func ValueRecordKey(requestID uint64) string {
+	return QueriedRecordPrefix + strconv.FormatUint(requestID, 10)
+}
+
+// EncodeValueRecord encodes the record for storing in the DB.
+func EncodeValueRecord(r *ValueRecord) ([]byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding value record")
+	}
+	return data, nil
+}
+
+// DecodeValueRecord decodes a record stored in the DB.
+func DecodeValueRecord(data []byte) (*ValueRecord, error) {
+	r := &ValueRecord{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, errors.Wrap(err, "decoding value record")
+	}
+	if r.Value == nil {
+		return nil, errors.New("value record without a value")
+	}
+	return r, nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// WhitelistEntry is a miner allowed to use the data server.
+type WhitelistEntry struct {
+	*MinerActivity
+	// Removable is false for the miners whitelisted in the config file
+	// and for the data server itself.
+	Removable bool `json:"removable"`
+}
+
+// WhitelistAdmin is implemented by data proxies that can
+// change the whitelist of a running data server.
+type WhitelistAdmin interface {
+	// WhitelistAdd allows the miner to use the data server.
+	WhitelistAdd(address string) error
+	// WhitelistRemove revokes the access of a miner added with WhitelistAdd.
+	WhitelistRemove(address string) error
+	// Whitelist returns all whitelisted miners with their activity.
+	Whitelist() ([]*WhitelistEntry, error)
+}
+
+// WhitelistReloader is implemented by data proxies that can
+// apply changes of the config file whitelist without a restart.
+type WhitelistReloader interface {
+	// ReloadWhitelist replaces the miners whitelisted in the config file.
+	// The miners added at runtime stay whitelisted.
+	ReloadWhitelist(configured []string) error
+}
+
+// StoredWhitelist returns the miners added to the whitelist at runtime.
+func StoredWhitelist(localDB DB) ([]string, error) {
+	data, err := localDB.Get(WhitelistKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the stored whitelist")
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var addrs []string
+	if err := json.Unmarshal(data, &addrs); err != nil {
+		return nil, errors.Wrap(err, "decoding the stored whitelist")
+	}
+	return addrs, nil
+}
+
+func storeWhitelist(localDB DB, addrs []string) error {
+	sort.Strings(addrs)
+	data, err := json.Marshal(addrs)
+	if err != nil {
+		return errors.Wrap(err, "encoding the whitelist")
+	}
+	return errors.Wrap(localDB.Put(WhitelistKey, data), "storing the whitelist")
+}
+
+// OpenWhitelistAdmin connects to the data server running with the current config
+// using the same settings as a remote miner.
+func OpenWhitelistAdmin() (WhitelistAdmin, error) {
+	return openDataServerAdmin()
+}
+
+// openDataServerAdmin connects to the data server listening on the configured host and port.
+func openDataServerAdmin() (*remoteImpl, error) {
+	cfg := config.GetConfig()
+	host := cfg.DataServer.ListenHost
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	proxy, err := openRemoteDB(nil, []config.RemoteDB{{
+		Host:    host,
+		Port:    cfg.DataServer.ListenPort,
+		Address: cfg.PublicAddress,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return proxy, nil
+}
+
+// loadWhitelist builds the whitelist from the config file and the stored miners.
+// The data server itself is always whitelisted so that it can be managed with its own key.
+// Must be called with the write lock held once the data server is running.
+func (i *remoteImpl) loadWhitelist(configured []string) error {
+	i.configWhitelist = map[string]bool{i.publicAddress: true}
+	for _, a := range configured {
+		i.configWhitelist[minerAddress(a)] = true
+	}
+	stored, err := StoredWhitelist(i.localDB)
+	if err != nil {
+		return err
+	}
+	addrs := make([]string, 0, len(i.configWhitelist)+len(stored))
+	for a := range i.configWhitelist {
+		addrs = append(addrs, a)
+	}
+	return i.setWhitelist(append(addrs, stored...))
+}
+
+func (i *remoteImpl) ReloadWhitelist(configured []string) error {
+	i.rwLock.Lock()
+	defer i.rwLock.Unlock()
+	if err := i.loadWhitelist(configured); err != nil {
+		return err
+	}
+	i.log.Info("Reloaded the whitelist, %v miners whitelisted", len(i.whitelist))
+	return nil
+}
+
+// setWhitelist replaces the whitelisted miners keeping the
+// nonce history of the miners that stay whitelisted.
+func (i *remoteImpl) setWhitelist(addrs []string) error {
+	wlMap := make(map[string]bool)
+	wlLRU := make(map[string]*lru.ARCCache)
+	for _, a := range addrs {
+		asStr := minerAddress(a)
+		hist := i.wlHistory[asStr]
+		if hist == nil {
+			var err error
+			hist, err = lru.NewARC(_nonceHistorySize)
+			if err != nil {
+				return err
+			}
+		}
+		wlLRU[asStr] = hist
+		wlMap[asStr] = true
+	}
+	for a := range i.whitelist {
+		if !wlMap[a] {
+			i.registry.remove(a)
+		}
+	}
+	for a := range wlMap {
+		i.registry.add(a)
+	}
+	i.whitelist = wlMap
+	i.wlHistory = wlLRU
+	return nil
+}
+
+// updateWhitelist applies an add or remove request made with the data server key.
+// Must be called with the write lock held.
+func (i *remoteImpl) updateWhitelist(key string, value []byte) error {
+	addr := string(value)
+	if !common.IsHexAddress(addr) {
+		return errors.Errorf("invalid miner address:%v", addr)
+	}
+	addr = minerAddress(addr)
+	stored, err := StoredWhitelist(i.localDB)
+	if err != nil {
+		return err
+	}
+	var updated []string
+	for _, a := range stored {
+		if a != addr {
+			updated = append(updated, a)
+		}
+	}
+	switch key {
+	case WhitelistAddKey:
+		if i.configWhitelist[addr] {
+			return nil
+		}
+		updated = append(updated, addr)
+		i.log.Info("Miner %v added to the whitelist", addr)
+	case WhitelistRemoveKey:
+		if i.configWhitelist[addr] {
+			return errors.Errorf("%v is whitelisted in the config file", addr)
+		}
+		if len(updated) == len(stored) {
+			return errors.Errorf("%v is not whitelisted", addr)
+		}
+		i.log.Info("Miner %v removed from the whitelist", addr)
+	}
+	if err := storeWhitelist(i.localDB, updated); err != nil {
+		return err
+	}
+	all := append([]string(nil), updated...)
+	for a := range i.configWhitelist {
+		all = append(all, a)
+	}
+	return i.setWhitelist(all)
+}
+
+// whitelistEntries lists the whitelisted miners with their activity.
+// Must be called with the read lock held.
+func (i *remoteImpl) whitelistEntries() ([]byte, error) {
+	miners, err := i.registry.Miners()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*WhitelistEntry, 0, len(miners))
+	for _, m := range miners {
+		if !i.whitelist[m.Address] {
+			continue
+		}
+		entries = append(entries, &WhitelistEntry{MinerActivity: m, Removable: !i.configWhitelist[m.Address]})
+	}
+	return json.Marshal(entries)
+}
+
+// isWhitelistAdmin checks whether the key changes the whitelist.
+func isWhitelistAdmin(key string) bool {
+	return key == WhitelistAddKey || key == WhitelistRemoveKey
+}
+
+func (i *remoteImpl) WhitelistAdd(address string) error {
+	_, err := i.Put(WhitelistAddKey, []byte(address))
+	return err
+}
+
+func (i *remoteImpl) WhitelistRemove(address string) error {
+	_, err := i.Put(WhitelistRemoveKey, []byte(address))
+	return err
+}
+
+func (i *remoteImpl) Whitelist() ([]*WhitelistEntry, error) {
+	data, err := i.Get(WhitelistKey)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*WhitelistEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "decoding the whitelist")
+	}
+	return entries, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestWhitelistAdmin(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	miner := "0x053b09e98ede40997546e8bb812cd838f18bb146"
+
+	_, DB, remote := startTestDataServer(t)
+	admin, err := openRemoteDB(nil, []config.RemoteDB{remote})
+	testutil.Ok(t, err)
+
+	testutil.Ok(t, admin.WhitelistAdd(miner))
+	stored, err := StoredWhitelist(DB)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{miner}, stored)
+
+	entries, err := admin.Whitelist()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(entries))
+	testutil.Equals(t, miner, entries[0].Address)
+	testutil.Assert(t, entries[0].Removable, "expected the added miner to be removable")
+	testutil.Assert(t, !entries[1].Removable, "expected the configured miner not to be removable")
+
+	// The stored whitelist is used after a restart.
+	restarted, err := openRemoteDB(DB, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, restarted.whitelist[miner], "expected the stored miner to be whitelisted")
+
+	testutil.NotOk(t, admin.WhitelistRemove(cfg.ServerWhitelist[0]))
+	testutil.Ok(t, admin.WhitelistRemove(miner))
+	testutil.NotOk(t, admin.WhitelistRemove(miner))
+	entries, err = admin.Whitelist()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(entries))
+	testutil.NotOk(t, admin.WhitelistAdd("not an address"))
+}
+
+func TestWhitelistReload(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	configured := "0x0000000000000000000000000000000000000002"
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8", configured}
+	added := "0x053b09e98ede40997546e8bb812cd838f18bb146"
+	replaced := "0x0000000000000000000000000000000000000001"
+
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	remote, err := openRemoteDB(DB, nil)
+	testutil.Ok(t, err)
+	testutil.Ok(t, remote.updateWhitelist(WhitelistAddKey, []byte(added)))
+	testutil.Assert(t, remote.whitelist[configured], "expected the configured miner to be whitelisted")
+
+	// The miners added at runtime and the data server itself stay whitelisted.
+	testutil.Ok(t, remote.ReloadWhitelist([]string{replaced}))
+	testutil.Assert(t, !remote.whitelist[configured], "expected the miner removed from the config file to be removed")
+	testutil.Assert(t, remote.whitelist[replaced], "expected the miner added to the config file to be whitelisted")
+	testutil.Assert(t, remote.whitelist[added], "expected the stored miner to stay whitelisted")
+	testutil.Assert(t, remote.whitelist[remote.publicAddress], "expected the data server to stay whitelisted")
+	testutil.Assert(t, remote.configWhitelist[replaced], "expected the miner added to the config file not to be removable")
+}
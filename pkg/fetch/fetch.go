@@ -0,0 +1,285 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+// Package fetch sends HTTP requests with retries, rate limits and size limits.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+var fetchLog = util.NewLogger("fetch", "Fetcher")
+
+var (
+	attempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: "fetch",
+		Name:      "attempts_total",
+		Help:      "The number of attempts of every request by result, ok, error or the status code of a failed response",
+	}, []string{"request", "result"})
+	attemptDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "telliot",
+		Subsystem: "fetch",
+		Name:      "attempt_duration_seconds",
+		Help:      "The duration of every attempt of a request",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"request"})
+)
+
+// Limiter holds back requests, e.g. to stay below the rate limit of a host.
+type Limiter interface {
+	// Wait blocks until the request is allowed or fails when that is after the deadline of the context.
+	Wait(ctx context.Context) error
+	// Block holds back all requests for the given time, after a server asked to retry later.
+	Block(d time.Duration)
+}
+
+// Request is a request to send until it succeeds or its context is done.
+type Request struct {
+	// Method defaults to GET.
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+	// Name is shown in logs, errors and metrics instead of the URL
+	// so that the secrets in the URL aren't leaked. Defaults to the URL.
+	Name string
+	// Limiter is waited for before every attempt, nil for no limit.
+	Limiter Limiter
+}
+
+func (r *Request) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.URL
+}
+
+// Fetcher sends the requests with the retry policy of the config.
+// It is safe for concurrent use.
+type Fetcher struct {
+	client          *http.Client
+	maxAttempts     int
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	maxResponseSize int64
+}
+
+// NewTransport creates an http transport that uses the proxy of the config
+// or the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env variables when it isn't set.
+func NewTransport(cfg config.Fetch, tlsConfig *tls.Config) (*http.Transport, error) {
+	// The same settings as the http.DefaultTransport.
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing the proxy URL")
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport, nil
+}
+
+// New creates a fetcher that sends the requests with the given transport.
+func New(cfg config.Fetch, transport http.RoundTripper) *Fetcher {
+	minBackoff := cfg.MinBackoff.Duration
+	if minBackoff <= 0 {
+		minBackoff = 500 * time.Millisecond
+	}
+	maxBackoff := cfg.MaxBackoff.Duration
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	return &Fetcher{
+		client:          &http.Client{Transport: transport},
+		maxAttempts:     cfg.MaxAttempts,
+		minBackoff:      minBackoff,
+		maxBackoff:      maxBackoff,
+		maxResponseSize: cfg.MaxResponseSize,
+	}
+}
+
+// Do sends the request until it gets a successful response and returns its body.
+// Network errors, server errors, 408 and 429 responses are retried
+// with an exponential backoff until the context is done or the attempts of the config are used up.
+func (f *Fetcher) Do(ctx context.Context, req *Request) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; f.maxAttempts <= 0 || attempt < f.maxAttempts; attempt++ {
+		if attempt > 0 {
+			fetchLog.Warn("Trying fetch of %s again: %v", req, lastErr)
+		}
+		if req.Limiter != nil {
+			if err := req.Limiter.Wait(ctx); err != nil {
+				return nil, giveUp(req, lastErr, err)
+			}
+		}
+		data, retryAfter, err := f.attempt(ctx, req)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if _, ok := err.(permanentError); ok {
+			return nil, err
+		}
+		wait := retryAfter
+		if wait > 0 && req.Limiter != nil {
+			// Hold back the other requests to the host too.
+			req.Limiter.Block(wait)
+		}
+		if wait == 0 {
+			wait = f.backoff(attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return nil, giveUp(req, lastErr, errors.New("request timeout"))
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, giveUp(req, lastErr, err)
+		}
+	}
+	return nil, giveUp(req, lastErr, errors.Errorf("%v attempts", f.maxAttempts))
+}
+
+func giveUp(req *Request, lastErr, reason error) error {
+	if lastErr == nil {
+		return errors.Wrapf(reason, "giving up fetch request %s", req)
+	}
+	return errors.Wrapf(lastErr, "giving up fetch request after %v, last error", reason)
+}
+
+// permanentError is an error that doesn't go away with retries.
+type permanentError struct {
+	error
+}
+
+// attempt sends the request once. It returns the wait asked by the server
+// with a Retry-After header when the request failed.
+func (f *Fetcher) attempt(ctx context.Context, req *Request) ([]byte, time.Duration, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequest(method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		// Not wrapped as the error contains the URL.
+		return nil, 0, permanentError{errors.Errorf("invalid request for %s", req)}
+	}
+	httpReq = httpReq.WithContext(ctx)
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+
+	start := time.Now()
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		attemptDuration.WithLabelValues(req.String()).Observe(time.Since(start).Seconds())
+		attempts.WithLabelValues(req.String(), "error").Inc()
+		if uerr, ok := err.(*url.Error); ok {
+			uerr.URL = req.String()
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if f.maxResponseSize > 0 {
+		body = io.LimitReader(resp.Body, f.maxResponseSize+1)
+	}
+	data, err := ioutil.ReadAll(body)
+	attemptDuration.WithLabelValues(req.String()).Observe(time.Since(start).Seconds())
+	if err != nil {
+		attempts.WithLabelValues(req.String(), "error").Inc()
+		return nil, 0, errors.Wrap(err, "read response body")
+	}
+	if f.maxResponseSize > 0 && int64(len(data)) > f.maxResponseSize {
+		attempts.WithLabelValues(req.String(), "error").Inc()
+		return nil, 0, permanentError{errors.Errorf("response of %s larger than %v bytes", req, f.maxResponseSize)}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempts.WithLabelValues(req.String(), strconv.Itoa(resp.StatusCode)).Inc()
+		if len(data) > 200 {
+			data = data[:200]
+		}
+		err := errors.Errorf("response from %s with status code:%d, payload:%s", req, resp.StatusCode, data)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+			return nil, retryAfter(resp), err
+		case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode >= 500:
+			return nil, 0, err
+		default:
+			return nil, 0, permanentError{err}
+		}
+	}
+	attempts.WithLabelValues(req.String(), "ok").Inc()
+	return data, 0, nil
+}
+
+// backoff returns the wait before a retry, doubling with every attempt up to the maximum.
+// Half of the wait is random so that the retries of requests failing together spread out.
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	d := f.maxBackoff
+	if attempt < 32 && f.minBackoff<<uint(attempt) < f.maxBackoff && f.minBackoff<<uint(attempt) > 0 {
+		d = f.minBackoff << uint(attempt)
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter parses the Retry-After header of a response,
+// it returns zero when there is none.
+func retryAfter(r *http.Response) time.Duration {
+	v := strings.TrimSpace(r.Header.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package fetch
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func newTestFetcher(t *testing.T) *Fetcher {
+	cfg := config.OpenTestConfig(t)
+	transport, err := NewTransport(cfg.Fetch, nil)
+	testutil.Ok(t, err)
+	return New(cfg.Fetch, transport)
+}
+
+func fetchWithTimeout(f *Fetcher, req *Request, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return f.Do(ctx, req)
+}
+
+func TestFetchRetry(t *testing.T) {
+	f := newTestFetcher(t)
+	res, err := fetchWithTimeout(f, &Request{URL: "https://api.binance.com/api/v1/klines?symbol=ETHBTC&interval=1d&limit=1"}, 5*time.Second)
+	testutil.Ok(t, err)
+	t.Logf("Result from query: %s\n", string(res))
+}
+
+func TestFetchWithErrors(t *testing.T) {
+	f := newTestFetcher(t)
+	_, err := fetchWithTimeout(f, &Request{URL: "https://badendpoint.com/api/v1/klines?symbol=ETHBTC&interval=1d&limit=1"}, 2*time.Second)
+	testutil.NotOk(t, err, "Bad endpoint test should have errored")
+}
+
+func TestFetchBodyError(t *testing.T) {
+	f := newTestFetcher(t)
+	_, err := fetchWithTimeout(f, &Request{URL: "https://api.binance.com/api/v1/klines?symbol=BADPAIR&interval=1d&limit=1"}, time.Second)
+	testutil.NotOk(t, err, "Bad endpoint test should have errored")
+}
+
+func TestFetchPost(t *testing.T) {
+	f := newTestFetcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		if r.Method != http.MethodPost || r.Header.Get("X-Api-Key") != "secret" || string(body) != `{"query":"{ pairs { id } }"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err = w.Write([]byte(`{"data":{"pairs":[{"id":"1"}]}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	req := &Request{
+		URL:    srv.URL + "?key=secret",
+		Method: http.MethodPost,
+		Header: http.Header{"X-Api-Key": []string{"secret"}},
+		Body:   []byte(`{"query":"{ pairs { id } }"}`),
+		Name:   srv.URL + "?key=${API_KEY}",
+	}
+	res, err := fetchWithTimeout(f, req, time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"data":{"pairs":[{"id":"1"}]}}`, string(res))
+
+	// The errors show the name of the request instead of the URL with the secrets.
+	srv.Close()
+	_, err = fetchWithTimeout(f, req, time.Second)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, !strings.Contains(err.Error(), "secret"), "expected no secrets in the error:%v", err)
+}
+
+type testLimiter struct {
+	mtx     sync.Mutex
+	waits   int
+	blocked time.Duration
+}
+
+func (l *testLimiter) Wait(ctx context.Context) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.waits++
+	return nil
+}
+
+func (l *testLimiter) Block(d time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.blocked = d
+}
+
+func TestRetryAfter(t *testing.T) {
+	f := newTestFetcher(t)
+	var mtx sync.Mutex
+	var requests []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		requests = append(requests, time.Now())
+		if len(requests) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, err := w.Write([]byte(`{"price": 1}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	limiter := &testLimiter{}
+	res, err := fetchWithTimeout(f, &Request{URL: srv.URL, Limiter: limiter}, 3*time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"price": 1}`, string(res))
+	testutil.Equals(t, 2, len(requests))
+	testutil.Assert(t, requests[1].Sub(requests[0]) >= time.Second, "retried before the Retry-After:%v", requests[1].Sub(requests[0]))
+	testutil.Equals(t, 2, limiter.waits)
+	testutil.Equals(t, time.Second, limiter.blocked)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var mtx sync.Mutex
+	count := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		count[r.URL.Path]++
+		mtx.Unlock()
+		switch r.URL.Path {
+		case "/notFound":
+			w.WriteHeader(http.StatusNotFound)
+		case "/serverError":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/large":
+			_, err := w.Write(make([]byte, 101))
+			testutil.Ok(t, err)
+		}
+	}))
+	defer srv.Close()
+
+	config.OpenTestConfig(t)
+	f := New(config.Fetch{
+		MaxAttempts:     3,
+		MaxResponseSize: 100,
+		MinBackoff:      config.Duration{Duration: time.Millisecond},
+		MaxBackoff:      config.Duration{Duration: 2 * time.Millisecond},
+	}, http.DefaultTransport)
+	for _, path := range []string{"/notFound", "/serverError", "/large"} {
+		_, err := fetchWithTimeout(f, &Request{URL: srv.URL + path}, time.Second)
+		testutil.NotOk(t, err, "expected an error for:%v", path)
+	}
+	// Only the server errors are retried.
+	testutil.Equals(t, 1, count["/notFound"])
+	testutil.Equals(t, 3, count["/serverError"])
+	testutil.Equals(t, 1, count["/large"])
+
+	// The context stops the retries.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := (New(config.Fetch{}, http.DefaultTransport)).Do(ctx, &Request{URL: srv.URL + "/serverError"})
+	testutil.NotOk(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	f := New(config.Fetch{
+		MinBackoff: config.Duration{Duration: 500 * time.Millisecond},
+		MaxBackoff: config.Duration{Duration: 4 * time.Second},
+	}, nil)
+	for attempt := 0; attempt < 100; attempt++ {
+		d := f.backoff(attempt)
+		expected := 4 * time.Second
+		if attempt <= 3 {
+			expected = 500 * time.Millisecond << uint(attempt)
+		}
+		testutil.Assert(t, d >= expected/2 && d <= expected, "unexpected backoff %v for attempt %v", d, attempt)
+	}
+}
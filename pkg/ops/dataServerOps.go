@@ -78,6 +78,11 @@ func (ops *DataServerOps) Start(ctx context.Context) error {
 	return nil
 }
 
+// ReloadIndexes applies the changes of the index file to the running data server.
+func (ops *DataServerOps) ReloadIndexes() error {
+	return ops.server.ReloadIndexes()
+}
+
 // Ready signals that the data server has completed at least one tracker cycle and any external dependencies
 // should be ready to use its initial output.
 func (ops *DataServerOps) Ready() chan bool {
@@ -314,7 +314,7 @@ func List(
 		for i := 0; i < numToShow; i++ {
 			dp := result.Datapoints[index+i]
 			t := result.Times[index+i]
-			fmt.Printf("        %f, ", dp)
+			fmt.Printf("        %f (sources within %.2f%%), ", dp, result.Dispersions[index+i]*100)
 			delta := disputedValTime.Sub(t)
 			if delta > 0 {
 				fmt.Printf("%.0fs before\n", delta.Seconds())
@@ -9,7 +9,6 @@ import (
 	"math/big"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -39,6 +38,12 @@ type SolutionSink interface {
 	Submit(context.Context, *pow.Result) (*types.Transaction, error)
 }
 
+// SolutionChecker is implemented by the solution sinks that check
+// the values of a solution before it is submitted.
+type SolutionChecker interface {
+	Check(*pow.Result) error
+}
+
 // MiningMgr manages mining, submiting a solution and requesting data.
 // In the tellor contract a solution is saved in slots where a value is valid only when it has 5 confirmed slots.
 // The manager tracks tx costs and profitThreshold is set it skips any transactions below the profit threshold.
@@ -151,7 +156,7 @@ func CreateMiningManager(
 		mng.solHandler = pool
 	} else {
 		mng.tasker = pow.CreateTasker(cfg, database)
-		mng.solHandler = pow.CreateSolutionHandler(cfg, submitter, database)
+		mng.solHandler = pow.CreateSolutionHandler(cfg, submitter, database, getter)
 	}
 	return mng, nil
 }
@@ -164,6 +169,13 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 	// Start the mining group.
 	go mgr.group.Mine(mgr.toMineInput, mgr.solutionOutput)
 
+	// Remote data servers push new challenges and values as they happen.
+	// Polling on every tick still runs as a fallback when the stream is down.
+	var updates <-chan struct{}
+	if streamer, ok := mgr.database.(db.DataStreamer); ok && mgr.cfg.Mine.RemoteDBStream && !mgr.cfg.EnablePoolWorker {
+		updates = streamer.Stream(ctx, streamKeys(mgr.cfg.PublicAddress))
+	}
+
 	for {
 		select {
 		// Boss wants us to quit for the day.
@@ -209,6 +221,27 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 				level.Debug(mgr.logger).Log("msg", "min transaction submit threshold hasn't passed", "minSubmitPeriod", mgr.cfg.MinSubmitPeriod, "lastSubmit", lastSubmit)
 				continue
 			}
+			// Check the values before claiming a slot so that a held back
+			// solution doesn't keep the slot from the other miners.
+			if checker, ok := mgr.solHandler.(SolutionChecker); ok {
+				if err := checker.Check(solution); err != nil {
+					level.Error(mgr.logger).Log("msg", "checking the solution values", "err", err)
+					continue
+				}
+			}
+			// Miners sharing a data server only submit when the data server grants a slot.
+			if claimer, ok := mgr.database.(db.SlotClaimer); ok && !mgr.cfg.EnablePoolWorker {
+				granted, err := claimer.ClaimSlot(solution.Work.Challenge.Challenge)
+				if err != nil {
+					level.Error(mgr.logger).Log("msg", "claiming a slot", "err", err)
+					continue
+				}
+				if !granted {
+					level.Info(mgr.logger).Log("msg", "all slots for the challenge are claimed by other miners, dropping the solution")
+					mgr.solutionPending = nil
+					continue
+				}
+			}
 			tx, err := mgr.solHandler.Submit(ctx, solution)
 			if err != nil {
 				level.Error(mgr.logger).Log("msg", "submiting a solution", "err", err)
@@ -226,6 +259,9 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 		// Time to check for a new challenge.
 		case <-ticker.C:
 			mgr.newWork()
+		// The data server pushed new data.
+		case <-updates:
+			mgr.newWork()
 		}
 	}
 }
@@ -261,12 +297,31 @@ func (mgr *MiningMgr) newWork() {
 	}()
 }
 
-func (mgr *MiningMgr) lastSubmit() (time.Duration, error) {
-	fromAddress := common.HexToAddress(mgr.cfg.PublicAddress)
-	pubKey := strings.ToLower(fromAddress.Hex())
+// streamKeys are the data server keys needed for new work.
+func streamKeys(pubKey string) []string {
+	keys := []string{
+		db.DifficultyKey,
+		db.CurrentChallengeKey,
+		db.RequestIdKey,
+		db.RequestIdKey0,
+		db.RequestIdKey1,
+		db.RequestIdKey2,
+		db.RequestIdKey3,
+		db.RequestIdKey4,
+		db.LastNewValueKey,
+		db.LastSubmissionKey,
+		db.MinerKey(pubKey, db.DisputeStatusKey),
+		db.MinerKey(pubKey, db.TimeOutKey),
+		db.ManualDataKey,
+	}
+	for id := range tracker.PSRs {
+		keys = append(keys, pow.ValueKeys(uint64(id))...)
+	}
+	return keys
+}
 
-	address := common.HexToAddress(pubKey)
-	dbKey := fmt.Sprintf("%s-%s", strings.ToLower(address.Hex()), db.TimeOutKey)
+func (mgr *MiningMgr) lastSubmit() (time.Duration, error) {
+	dbKey := db.MinerKey(mgr.cfg.PublicAddress, db.TimeOutKey)
 	last, err := mgr.database.Get(dbKey)
 	if err != nil {
 		return time.Duration(0), errors.Wrapf(err, "timeout retrieval error")
@@ -0,0 +1,66 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+var breakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "telliot",
+	Subsystem: "mining",
+	Name:      "circuit_breaker_open",
+	Help:      "Whether mining is halted because the sources of the request ID disagree, 1 is halted",
+}, []string{"request_id"})
+
+// breaker halts mining for challenges with request IDs whose sources
+// disagree more than the max dispersion. Mining resumes as soon as the
+// sources agree again.
+type breaker struct {
+	maxDispersion float64
+	open          map[uint64]bool
+	log           *util.Logger
+}
+
+func newBreaker(maxDispersion float64) *breaker {
+	return &breaker{
+		maxDispersion: maxDispersion,
+		open:          make(map[uint64]bool),
+		log:           util.NewLogger("pow", "CircuitBreaker"),
+	}
+}
+
+// tripped reports whether the sources of the value disagree too much to mine it.
+// Values without a record are never considered in disagreement.
+func (b *breaker) tripped(requestID uint64, rec *db.ValueRecord) bool {
+	tripped := dispersed(b.maxDispersion, rec)
+	if tripped != b.open[requestID] {
+		if tripped {
+			b.log.Error("halting mining, the sources of request id %v disagree by %.2f%%, max allowed:%.2f%%",
+				requestID, rec.Dispersion*100, b.maxDispersion*100)
+		} else {
+			b.log.Info("resuming mining, the sources of request id %v agree again", requestID)
+		}
+	}
+	if tripped {
+		b.open[requestID] = true
+		breakerOpen.With(prometheus.Labels{"request_id": strconv.FormatUint(requestID, 10)}).Set(1)
+		return true
+	}
+	if b.open[requestID] {
+		delete(b.open, requestID)
+		breakerOpen.With(prometheus.Labels{"request_id": strconv.FormatUint(requestID, 10)}).Set(0)
+	}
+	return false
+}
+
+// dispersed reports whether the sources of the value disagree more than the max dispersion.
+func dispersed(maxDispersion float64, rec *db.ValueRecord) bool {
+	return maxDispersion > 0 && rec != nil && rec.Dispersion > maxDispersion
+}
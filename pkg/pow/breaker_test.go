@@ -0,0 +1,26 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestBreaker(t *testing.T) {
+	config.OpenTestConfig(t)
+	b := newBreaker(0.1)
+	testutil.Assert(t, !b.tripped(1, nil), "values without a record shouldn't trip the breaker")
+	testutil.Assert(t, !b.tripped(1, &db.ValueRecord{Dispersion: 0.05}), "agreeing sources shouldn't trip the breaker")
+	testutil.Assert(t, b.tripped(1, &db.ValueRecord{Dispersion: 0.3}), "disagreeing sources should trip the breaker")
+	testutil.Assert(t, b.tripped(1, &db.ValueRecord{Dispersion: 0.2}), "the breaker should stay open while the sources disagree")
+	testutil.Assert(t, !b.tripped(2, &db.ValueRecord{Dispersion: 0}), "other request IDs shouldn't be affected")
+	testutil.Assert(t, !b.tripped(1, &db.ValueRecord{Dispersion: 0.01}), "the breaker should close when the sources agree again")
+
+	disabled := newBreaker(0)
+	testutil.Assert(t, !disabled.tripped(1, &db.ValueRecord{Dispersion: 0.3}), "a zero max dispersion should disable the breaker")
+}
@@ -5,18 +5,13 @@ package pow
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"math"
 	"math/big"
 	"math/rand"
-	"os"
-	"path/filepath"
-	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/util"
@@ -39,27 +34,32 @@ const (
  * - If the miner address is in dispute, end program entirely
  * - If there is a pending txn for the miner address, issue cancel
  * - If there is no price data available for the current request, issue cancel
+ * - If the sources of any of the current requests disagree, issue cancel
  * - Otherwise, push new challenge to output channel
  */
 
 type MiningTasker struct {
 	log           *util.Logger
+	cfg           *config.Config
 	proxy         db.DataServerProxy
 	pubKey        string
 	currChallenge *MiningChallenge
+	breaker       *breaker
 }
 
 func CreateTasker(cfg *config.Config, proxy db.DataServerProxy) *MiningTasker {
 
 	return &MiningTasker{
-		proxy:  proxy,
-		pubKey: "0x" + cfg.PublicAddress,
-		log:    util.NewLogger("pow", "MiningTasker"),
+		cfg:     cfg,
+		proxy:   proxy,
+		pubKey:  "0x" + cfg.PublicAddress,
+		breaker: newBreaker(cfg.Mine.MaxDispersion),
+		log:     util.NewLogger("pow", "MiningTasker"),
 	}
 }
 
 func (mt *MiningTasker) GetWork(chan *Work) (*Work, bool) {
-	dispKey := mt.pubKey + "-" + db.DisputeStatusKey
+	dispKey := db.MinerKey(mt.pubKey, db.DisputeStatusKey)
 	keys := []string{
 		db.DifficultyKey,
 		db.CurrentChallengeKey,
@@ -132,27 +132,25 @@ func (mt *MiningTasker) GetWork(chan *Work) (*Work, bool) {
 	reqIDs[4] = r
 
 	for i := 0; i < 5; i++ {
-		valKey := fmt.Sprintf("%s%d", db.QueriedValuePrefix, reqIDs[i].Uint64())
-		m2, err := mt.proxy.BatchGet([]string{valKey})
+		val, rec, err := lookupValue(mt.cfg, mt.proxy, reqIDs[i].Uint64())
+		if errors.Is(err, errUnusableValue) {
+			mt.log.Warn("Not mining with unusable pricing data: %v", err)
+			return nil, false
+		}
+		if mt.breaker.tripped(reqIDs[i].Uint64(), rec) {
+			return nil, false
+		}
 		if err != nil {
 			mt.log.Info("Could not retrieve pricing data for current request id:%v", err)
 			//return nil, false
 		}
-		val := m2[valKey]
 		if len(val) == 0 {
-			cfg := config.GetConfig()
-			indexPath := filepath.Join(cfg.ConfigFolder, "manualData.json")
-			jsonFile, err := os.Open(indexPath)
+			manual, err := manualValue(mt.cfg, mt.proxy, reqIDs[i].Uint64())
 			if err != nil {
+				mt.log.Error("Not mining with unusable manual data: %v", err)
 				return nil, false
 			}
-			defer jsonFile.Close()
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			var result map[string]map[string]uint
-			_ = json.Unmarshal([]byte(byteValue), &result)
-			_id := strconv.FormatUint(reqIDs[i].Uint64(), 10)
-			val := result[_id]["VALUE"]
-			if val == 0 {
+			if manual == nil {
 				mt.log.Info("Pricing data not available for request %d", reqIDs[i].Uint64())
 				return nil, false
 			}
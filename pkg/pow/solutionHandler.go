@@ -5,13 +5,7 @@ package pow
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"math/big"
-	"os"
-	"path/filepath"
-	"strconv"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -31,53 +25,56 @@ import (
 
 type SolutionHandler struct {
 	log              *util.Logger
+	cfg              *config.Config
 	proxy            db.DataServerProxy
 	currentChallenge *MiningChallenge
 	currentNonce     string
 	currentValues    [5]*big.Int
 	submitter        tellorCommon.TransactionSubmitter
+	guard            *submitGuard
+	// checked is the solution whose values are in currentValues.
+	checked *Result
 }
 
-func CreateSolutionHandler(cfg *config.Config, submitter tellorCommon.TransactionSubmitter, proxy db.DataServerProxy) *SolutionHandler {
+func CreateSolutionHandler(cfg *config.Config, submitter tellorCommon.TransactionSubmitter, proxy db.DataServerProxy, getter LastValueGetter) *SolutionHandler {
 
 	return &SolutionHandler{
+		cfg:       cfg,
 		proxy:     proxy,
 		submitter: submitter,
+		guard:     newSubmitGuard(cfg.Mine.SubmitGuard, getter),
 		log:       util.NewLogger("pow", "SolutionHandler"),
 	}
 }
 
-func (s *SolutionHandler) Submit(ctx context.Context, result *Result) (*types.Transaction, error) {
+// Check looks up the values of the solution and checks that they can be submitted.
+// Submit checks the solution again unless it was the last one checked.
+func (s *SolutionHandler) Check(result *Result) error {
 	challenge := result.Work.Challenge
-	nonce := result.Nonce
-	s.currentChallenge = challenge
-	s.currentNonce = nonce
+	s.checked = nil
 
+	var values [5]*big.Int
+	var records [5]*db.ValueRecord
 	for i := 0; i < 5; i++ {
-		valKey := fmt.Sprintf("%s%d", db.QueriedValuePrefix, challenge.RequestIDs[i].Uint64())
-		m, err := s.proxy.BatchGet([]string{valKey})
+		val, rec, err := lookupValue(s.cfg, s.proxy, challenge.RequestIDs[i].Uint64())
 		if err != nil {
-			return nil, errors.Wrapf(err, "could not retrieve pricing data for current request id")
+			return errors.Wrapf(err, "could not retrieve pricing data for current request id")
+		}
+		records[i] = rec
+		if dispersed(s.cfg.Mine.MaxDispersion, rec) {
+			return errors.Errorf("the sources of request id %v disagree by %.2f%%, max allowed:%.2f%%",
+				challenge.RequestIDs[i], rec.Dispersion*100, s.cfg.Mine.MaxDispersion*100)
 		}
-		val := m[valKey]
 		var value *big.Int
 		if len(val) == 0 {
-			cfg := config.GetConfig()
-			indexPath := filepath.Join(cfg.ConfigFolder, "manualData.json")
-			jsonFile, err := os.Open(indexPath)
+			value, err = manualValue(s.cfg, s.proxy, challenge.RequestIDs[i].Uint64())
 			if err != nil {
-				return nil, errors.Wrapf(err, "manualData read Error")
+				s.log.Error("not submitting with unusable manual data: %v", err)
+				return errors.Wrap(err, "reading the manual value")
 			}
-			defer jsonFile.Close()
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			var result map[string]map[string]uint
-			_ = json.Unmarshal([]byte(byteValue), &result)
-			_id := strconv.FormatUint(challenge.RequestIDs[i].Uint64(), 10)
-			val := result[_id]["VALUE"]
-			if val == 0 {
-				return nil, errors.Errorf("could not retrieve pricing data for current request id")
+			if value == nil {
+				return errors.Errorf("could not retrieve pricing data for current request id")
 			}
-			value = big.NewInt(int64(val))
 		} else {
 			value, err = hexutil.DecodeBig(string(val))
 			if err != nil {
@@ -85,15 +82,33 @@ func (s *SolutionHandler) Submit(ctx context.Context, result *Result) (*types.Tr
 					s.log.Error("problem decoding price value prior to submitting solution: %v\n", err)
 					if len(val) == 0 {
 						s.log.Error("0 value being submitted")
-						s.currentValues[i] = big.NewInt(0)
+						values[i] = big.NewInt(0)
 					}
 					continue
 				}
-				return nil, errors.Errorf("no value in database,  reg id:%v", challenge.RequestIDs[i].Uint64())
+				return errors.Errorf("no value in database,  reg id:%v", challenge.RequestIDs[i].Uint64())
 			}
 		}
-		s.currentValues[i] = value
+		values[i] = value
+	}
+	if err := s.guard.check(challenge.RequestIDs, values, records); err != nil {
+		return errors.Wrap(err, "holding the solution")
+	}
+	s.checked = result
+	s.currentValues = values
+	return nil
+}
+
+func (s *SolutionHandler) Submit(ctx context.Context, result *Result) (*types.Transaction, error) {
+	if s.checked != result {
+		if err := s.Check(result); err != nil {
+			return nil, err
+		}
 	}
+	// Values are checked again for the next submission.
+	s.checked = nil
+	s.currentChallenge = result.Work.Challenge
+	s.currentNonce = result.Nonce
 	tx, err := s.submitter.Submit(ctx, s.proxy, "submitSolution", s.submit)
 	if err != nil {
 		return nil, errors.Wrap(err, "submitting solution txn")
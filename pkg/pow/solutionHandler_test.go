@@ -0,0 +1,83 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	tellorCommon "github.com/tellor-io/telliot/pkg/common"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// countingProxy counts the lookups.
+type countingProxy struct {
+	db.DataServerProxy
+	lookups int
+}
+
+func (c *countingProxy) BatchGet(keys []string) (map[string][]byte, error) {
+	c.lookups++
+	return c.DataServerProxy.BatchGet(keys)
+}
+
+type countingSubmitter struct {
+	submits int
+}
+
+func (c *countingSubmitter) Submit(ctx context.Context, proxy db.DataServerProxy, ctxName string, factoryFn tellorCommon.TransactionGeneratorFN) (*types.Transaction, error) {
+	c.submits++
+	return &types.Transaction{}, nil
+}
+
+func TestSolutionHandlerCheck(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.Mine.MaxDispersion = 0.1
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	local, err := db.OpenLocalProxy(DB)
+	testutil.Ok(t, err)
+	proxy := &countingProxy{DataServerProxy: local}
+
+	putRecord := func(id uint64, dispersion float64) {
+		rec, err := db.EncodeValueRecord(&db.ValueRecord{
+			Value:      (*hexutil.Big)(big.NewInt(1000)),
+			Time:       time.Now(),
+			Confidence: 0.9,
+			Sources:    3,
+			Dispersion: dispersion,
+		})
+		testutil.Ok(t, err)
+		testutil.Ok(t, DB.Put(db.ValueRecordKey(id), rec))
+	}
+	var ids [5]*big.Int
+	for i := range ids {
+		ids[i] = big.NewInt(int64(i + 1))
+		putRecord(uint64(i+1), 0)
+	}
+	result := &Result{Work: &Work{Challenge: &MiningChallenge{RequestIDs: ids}}, Nonce: "1"}
+
+	submitter := &countingSubmitter{}
+	handler := CreateSolutionHandler(cfg, submitter, proxy, lastValues{})
+	testutil.Ok(t, handler.Check(result))
+	lookups := proxy.lookups
+	// The checked values are submitted without looking them up again.
+	_, err = handler.Submit(context.Background(), result)
+	testutil.Ok(t, err)
+	testutil.Equals(t, lookups, proxy.lookups)
+	testutil.Equals(t, 1, submitter.submits)
+
+	// Values of disagreeing sources aren't submitted.
+	putRecord(3, 0.2)
+	testutil.NotOk(t, handler.Check(result))
+	_, err = handler.Submit(context.Background(), result)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 1, submitter.submits)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/tracker"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+var guardHolds = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: "mining",
+	Name:      "submit_guard_holds_total",
+	Help:      "The total number of submissions held back because a value changed too much since the last on-chain value",
+}, []string{"request_id"})
+
+// LastValueGetter returns the last value accepted by the oracle for a request ID.
+type LastValueGetter interface {
+	GetLastNewValueById(opts *bind.CallOpts, _requestId *big.Int) (*big.Int, bool, error)
+}
+
+/**
+ * The submit guard compares the values of a solution with the last values accepted
+ * by the oracle. A solution with a value that jumped more than the configured amount
+ * is held back since a single bad API response could get the miner disputed.
+ * The solution stays pending so it is checked again with fresh values.
+ */
+
+type submitGuard struct {
+	cfg    config.SubmitGuard
+	getter LastValueGetter
+	log    *util.Logger
+}
+
+func newSubmitGuard(cfg config.SubmitGuard, getter LastValueGetter) *submitGuard {
+	return &submitGuard{
+		cfg:    cfg,
+		getter: getter,
+		log:    util.NewLogger("pow", "SubmitGuard"),
+	}
+}
+
+// maxChange returns the max relative change allowed for the request ID, zero when unlimited.
+// The most specific limit applies, a request ID limit over a symbol limit over a class limit over the default.
+func (g *submitGuard) maxChange(requestID int, at time.Time) float64 {
+	if max, ok := g.cfg.RequestIDs[requestID]; ok {
+		return max
+	}
+	var max float64
+	found := false
+	for _, symbol := range tracker.PSRSymbols(requestID, at) {
+		if m, ok := g.cfg.Symbols[symbol]; ok && (!found || m < max) {
+			max, found = m, true
+		}
+	}
+	if found {
+		return max
+	}
+	if max, ok := g.cfg.Classes[tracker.PSRClass(requestID)]; ok {
+		return max
+	}
+	return g.cfg.MaxChange
+}
+
+// check returns an error when any of the values changed more than allowed.
+// The records of the values give the source values to log, they can be nil.
+func (g *submitGuard) check(requestIDs [5]*big.Int, values [5]*big.Int, records [5]*db.ValueRecord) error {
+	now := time.Now()
+	var held []string
+	for i, id := range requestIDs {
+		if id == nil || values[i] == nil {
+			continue
+		}
+		max := g.maxChange(int(id.Int64()), now)
+		if max <= 0 {
+			continue
+		}
+		last, ok, err := g.getter.GetLastNewValueById(nil, id)
+		if err != nil {
+			return errors.Wrapf(err, "getting the last value of request id:%v", id)
+		}
+		if !ok || last.Sign() == 0 {
+			continue
+		}
+		change := relativeChange(last, values[i])
+		if change <= max {
+			continue
+		}
+		guardHolds.With(prometheus.Labels{"request_id": id.String()}).Inc()
+		g.log.Error("value of request id %v changed by %.2f%% from the last on-chain value %v to %v, max allowed:%.2f%%",
+			id, change*100, last, values[i], max*100)
+		// The data server stores the source values with the records,
+		// older data servers only have them in the local history of a local data server.
+		var sources map[string]float64
+		if records[i] != nil {
+			sources = records[i].SourceValues
+		}
+		if len(sources) == 0 {
+			sources = tracker.PSRSources(int(id.Int64()), now)
+		}
+		if len(sources) == 0 {
+			g.log.Error("no source values for request id %v, upgrade the data server to log them", id)
+		}
+		names := make([]string, 0, len(sources))
+		for source := range sources {
+			names = append(names, source)
+		}
+		sort.Strings(names)
+		for _, source := range names {
+			g.log.Error("request id %v source %v: %v", id, source, sources[source])
+		}
+		held = append(held, id.String())
+	}
+	if len(held) > 0 {
+		return errors.Errorf("values changed too much since the last on-chain values, request ids:%v", strings.Join(held, ","))
+	}
+	return nil
+}
+
+// relativeChange returns the change from last to val relative to last.
+func relativeChange(last, val *big.Int) float64 {
+	diff := new(big.Float).SetInt(new(big.Int).Sub(val, last))
+	change, _ := diff.Quo(diff, new(big.Float).SetInt(last)).Float64()
+	if change < 0 {
+		return -change
+	}
+	return change
+}
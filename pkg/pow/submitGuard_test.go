@@ -0,0 +1,59 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type lastValues map[int64]*big.Int
+
+func (l lastValues) GetLastNewValueById(opts *bind.CallOpts, requestID *big.Int) (*big.Int, bool, error) {
+	v, ok := l[requestID.Int64()]
+	return v, ok, nil
+}
+
+func TestSubmitGuard(t *testing.T) {
+	config.OpenTestConfig(t)
+	getter := lastValues{
+		1: big.NewInt(1000),
+		2: big.NewInt(1000),
+		3: big.NewInt(1000),
+	}
+	guard := newSubmitGuard(config.SubmitGuard{
+		MaxChange:  0.1,
+		RequestIDs: map[int]float64{3: 0},
+		Symbols:    map[string]float64{"BTC/USD": 0.5},
+		Classes:    map[string]float64{"eod": 0.3, "manual": 0},
+	}, getter)
+	ids := [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	values := func(v1, v2, v3 int64) [5]*big.Int {
+		return [5]*big.Int{big.NewInt(v1), big.NewInt(v2), big.NewInt(v3), big.NewInt(1), big.NewInt(1)}
+	}
+
+	// Request ID 4 has no on-chain value yet so it isn't checked.
+	testutil.Ok(t, guard.check(ids, values(1050, 1400, 1000), [5]*db.ValueRecord{}))
+	testutil.Ok(t, guard.check(ids, values(950, 600, 1000), [5]*db.ValueRecord{}))
+	// The limit of request ID 3 disables the check.
+	testutil.Ok(t, guard.check(ids, values(1000, 1000, 1000000), [5]*db.ValueRecord{}))
+
+	testutil.NotOk(t, guard.check(ids, values(1200, 1000, 1000), [5]*db.ValueRecord{}))
+	testutil.NotOk(t, guard.check(ids, values(800, 1000, 1000), [5]*db.ValueRecord{}))
+	// Request ID 2 is BTC/USD.
+	testutil.NotOk(t, guard.check(ids, values(1000, 1600, 1000), [5]*db.ValueRecord{}))
+
+	testutil.Equals(t, 0.5, guard.maxChange(2, time.Now()))
+	testutil.Equals(t, 0.1, guard.maxChange(1, time.Now()))
+	// Request ID 9 is the ETH/USD daily fixing and 42 the BTC/USD one.
+	testutil.Equals(t, 0.3, guard.maxChange(9, time.Now()))
+	testutil.Equals(t, 0.5, guard.maxChange(42, time.Now()))
+	testutil.Equals(t, 0.0, guard.maxChange(41, time.Now()))
+}
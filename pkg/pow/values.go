@@ -0,0 +1,85 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+)
+
+// errUnusableValue is returned for values that are too old or not confident enough to submit.
+var errUnusableValue = errors.New("value can't be submitted")
+
+// ValueKeys are the data server keys looked up for the value of a request ID.
+// Remote miners stream them so that the lookups don't need a request to the data server.
+// The data server still stores the plain value for remote miners from before the value records.
+func ValueKeys(requestID uint64) []string {
+	return []string{fmt.Sprintf("%s%d", db.QueriedValuePrefix, requestID), db.ValueRecordKey(requestID)}
+}
+
+// lookupValue returns the encoded value of the request ID or nil when the data server doesn't have one.
+// When the data server stores a record for the value it is returned as well and the value
+// is only returned if it is recent and confident enough.
+func lookupValue(cfg *config.Config, proxy db.DataServerProxy, requestID uint64) ([]byte, *db.ValueRecord, error) {
+	keys := ValueKeys(requestID)
+	valKey, recKey := keys[0], keys[1]
+	m, err := proxy.BatchGet(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(m[recKey]) == 0 {
+		return m[valKey], nil, nil
+	}
+	rec, err := db.DecodeValueRecord(m[recKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkValueRecord(cfg, rec, time.Now()); err != nil {
+		return nil, rec, errors.Wrapf(err, "request id:%v", requestID)
+	}
+	return []byte(hexutil.EncodeBig(rec.Value.ToInt())), rec, nil
+}
+
+func checkValueRecord(cfg *config.Config, rec *db.ValueRecord, now time.Time) error {
+	if age := now.Sub(rec.Time); cfg.Mine.MaxValueAge.Duration > 0 && age > cfg.Mine.MaxValueAge.Duration {
+		return errors.Wrapf(errUnusableValue, "computed %v ago, max age:%v", age.Round(time.Second), cfg.Mine.MaxValueAge.Duration)
+	}
+	if cfg.Mine.MinValueConfidence > 0 && rec.Confidence < cfg.Mine.MinValueConfidence {
+		return errors.Wrapf(errUnusableValue, "confidence %.2f, min confidence:%v", rec.Confidence, cfg.Mine.MinValueConfidence)
+	}
+	return nil
+}
+
+// manualValue returns the value entered with `telliot manual set` for the request ID or nil when there is none.
+// Expired entries and entries without an authorized signature are errors so that they are never submitted.
+func manualValue(cfg *config.Config, proxy db.DataServerProxy, requestID uint64) (*big.Int, error) {
+	data, err := proxy.Get(db.ManualDataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the manual data")
+	}
+	entries, err := db.DecodeManualData(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.RequestID != requestID {
+			continue
+		}
+		if err := e.Verify(cfg.ManualDataSigners); err != nil {
+			return nil, err
+		}
+		if e.Expired(time.Now()) {
+			return nil, errors.Errorf("manual value of request id %v expired at %v, update it with telliot manual set",
+				requestID, e.Expires.Format(time.RFC3339))
+		}
+		return e.Value.ToInt(), nil
+	}
+	return nil, nil
+}
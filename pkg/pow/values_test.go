@@ -0,0 +1,174 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/rest"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestLookupValue(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.Mine.MaxValueAge = config.Duration{Duration: 10 * time.Minute}
+	cfg.Mine.MinValueConfidence = 0.5
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocalProxy(DB)
+	testutil.Ok(t, err)
+
+	val := []byte(hexutil.EncodeBig(big.NewInt(1000)))
+	putRecord := func(age time.Duration, confidence float64) {
+		rec, err := db.EncodeValueRecord(&db.ValueRecord{
+			Value:      (*hexutil.Big)(big.NewInt(1000)),
+			Time:       time.Now().Add(-age),
+			Confidence: confidence,
+			Sources:    3,
+		})
+		testutil.Ok(t, err)
+		testutil.Ok(t, DB.Put(db.ValueRecordKey(1), rec))
+	}
+
+	// Values without a record are used as they are.
+	testutil.Ok(t, DB.Put(db.QueriedValuePrefix+"1", val))
+	got, _, err := lookupValue(cfg, proxy, 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, val, got)
+
+	putRecord(time.Minute, 0.9)
+	got, _, err = lookupValue(cfg, proxy, 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, val, got)
+
+	putRecord(time.Hour, 0.9)
+	_, _, err = lookupValue(cfg, proxy, 1)
+	testutil.Assert(t, errors.Is(err, errUnusableValue), "expected a stale value error:%v", err)
+
+	putRecord(time.Minute, 0.2)
+	_, _, err = lookupValue(cfg, proxy, 1)
+	testutil.Assert(t, errors.Is(err, errUnusableValue), "expected a low confidence error:%v", err)
+
+	// Zero disables the checks.
+	cfg.Mine.MaxValueAge = config.Duration{}
+	cfg.Mine.MinValueConfidence = 0
+	putRecord(time.Hour, 0.2)
+	got, _, err = lookupValue(cfg, proxy, 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, val, got)
+
+	got, _, err = lookupValue(cfg, proxy, 2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(got))
+}
+
+func TestManualValue(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocalProxy(DB)
+	testutil.Ok(t, err)
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	cfg.ManualDataSigners = []string{crypto.PubkeyToAddress(key.PublicKey).Hex()}
+
+	putEntry := func(expires time.Time) {
+		e, err := db.NewManualEntry(41, "111.683", 1000, expires)
+		testutil.Ok(t, err)
+		testutil.Ok(t, e.Sign(key))
+		data, err := json.Marshal([]*db.ManualEntry{e})
+		testutil.Ok(t, err)
+		testutil.Ok(t, DB.Put(db.ManualDataKey, data))
+	}
+
+	val, err := manualValue(cfg, proxy, 41)
+	testutil.Ok(t, err)
+	testutil.Assert(t, val == nil, "expected no value without an entry")
+
+	putEntry(time.Now().Add(time.Hour))
+	val, err = manualValue(cfg, proxy, 41)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(111683), val)
+
+	val, err = manualValue(cfg, proxy, 10)
+	testutil.Ok(t, err)
+	testutil.Assert(t, val == nil, "expected no value for other request ids")
+
+	putEntry(time.Now().Add(-time.Minute))
+	_, err = manualValue(cfg, proxy, 41)
+	testutil.NotOk(t, err, "expected an expired value to be an error")
+
+	// Entries of other signers are never used.
+	cfg.ManualDataSigners = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	putEntry(time.Now().Add(time.Hour))
+	_, err = manualValue(cfg, proxy, 41)
+	testutil.NotOk(t, err, "expected an unauthorized signer to be an error")
+}
+
+func TestLookupValueStreamed(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+
+	rec, err := db.EncodeValueRecord(&db.ValueRecord{
+		Value:      (*hexutil.Big)(big.NewInt(1000)),
+		Time:       time.Now(),
+		Confidence: 0.9,
+		Sources:    3,
+	})
+	testutil.Ok(t, err)
+	testutil.Ok(t, DB.Put(db.ValueRecordKey(1), rec))
+	testutil.Ok(t, DB.Put(db.QueriedValuePrefix+"1", []byte(hexutil.EncodeBig(big.NewInt(1000)))))
+
+	server, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	// Only the stream is served so any lookup that isn't streamed fails.
+	mux := http.NewServeMux()
+	mux.Handle(db.StreamPath, rest.CreateStreamRouter(server.(db.DataStreamer)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	cfg.Mine.RemoteDBHost = host
+	cfg.Mine.RemoteDBPort = uint(p)
+	cfg.Fetch.MaxAttempts = 1
+	client, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := client.(db.DataStreamer).Stream(ctx, append(ValueKeys(1), ValueKeys(2)...))
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update from the stream")
+	}
+
+	got, gotRec, err := lookupValue(cfg, client, 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte(hexutil.EncodeBig(big.NewInt(1000))), got)
+	testutil.Equals(t, 3, gotRec.Sources)
+
+	got, gotRec, err = lookupValue(cfg, client, 2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(got))
+	testutil.Assert(t, gotRec == nil, "expected no record")
+}
@@ -0,0 +1,365 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/tracker"
+	"golang.org/x/time/rate"
+)
+
+// APIPrefix is the path under which the versioned JSON API is served.
+const APIPrefix = "/api/v1/"
+
+// How long a client can stay idle before its rate limiter is dropped.
+const limiterIdleTimeout = 10 * time.Minute
+
+// APIRouter serves a read-only JSON view of the data server state.
+// Unlike the RemoteProxyRouter it doesn't require a signed request from a
+// whitelisted miner so it can be consumed by dashboards and other services.
+type APIRouter struct {
+	db       db.DB
+	registry *db.MinerRegistry
+	token    string
+	limit    rate.Limit
+	burst    int
+	logger   log.Logger
+
+	mtx      sync.Mutex
+	limiters map[string]*clientLimiter
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// CreateAPI creates a JSON API router reading from the given local DB.
+// The miners are listed only when a registry is given.
+// The bearer token is read from the environment and when not set the API is open to everyone.
+func CreateAPI(logger log.Logger, cfg *config.Config, DB db.DB, registry *db.MinerRegistry) *APIRouter {
+	limit := rate.Inf
+	if cfg.DataServer.API.RateLimit > 0 {
+		limit = rate.Limit(cfg.DataServer.API.RateLimit)
+	}
+	burst := cfg.DataServer.API.RateBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return &APIRouter{
+		db:       DB,
+		registry: registry,
+		token:    os.Getenv(config.APITokenEnvName),
+		limit:    limit,
+		burst:    burst,
+		logger:   log.With(logger, "component", "api"),
+		limiters: make(map[string]*clientLimiter),
+	}
+}
+
+// ValueResponse is the API representation of a request ID value.
+type ValueResponse struct {
+	RequestID   int     `json:"requestId"`
+	Value       string  `json:"value"`
+	Granularity int64   `json:"granularity"`
+	Price       float64 `json:"price"`
+	// Time, Confidence and Sources are the details of the value record when there is one.
+	Time       *time.Time `json:"time,omitempty"`
+	Confidence float64    `json:"confidence,omitempty"`
+	Sources    int        `json:"sources,omitempty"`
+}
+
+// HistoryResponse holds the recorded values of all sources for a symbol.
+type HistoryResponse struct {
+	Symbol  string                             `json:"symbol"`
+	From    time.Time                          `json:"from"`
+	To      time.Time                          `json:"to"`
+	Sources map[string][]*apiOracle.PriceStamp `json:"sources"`
+}
+
+// SourceResponse describes a single API that feeds one or more symbols.
+type SourceResponse struct {
+	Name    string                `json:"name"`
+	Symbols []string              `json:"symbols"`
+	Latest  *apiOracle.PriceStamp `json:"latest,omitempty"`
+	Health  tracker.SourceHealth  `json:"health"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (a *APIRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		a.writeError(w, http.StatusMethodNotAllowed, "only GET requests are supported")
+		return
+	}
+	if !a.authorized(req) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		a.writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+	if !a.allow(clientID(req)) {
+		a.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, APIPrefix)
+	switch {
+	case path == "values":
+		a.values(w)
+	case strings.HasPrefix(path, "values/"):
+		a.value(w, strings.TrimPrefix(path, "values/"))
+	case strings.HasPrefix(path, "history/"):
+		a.history(w, req, strings.TrimPrefix(path, "history/"))
+	case path == "sources":
+		a.sources(w)
+	case path == "miners":
+		a.miners(w)
+	default:
+		a.writeError(w, http.StatusNotFound, "unknown endpoint")
+	}
+}
+
+func (a *APIRouter) value(w http.ResponseWriter, id string) {
+	requestID, err := strconv.Atoi(id)
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, "invalid request id: "+id)
+		return
+	}
+	val, err := a.readValue(requestID)
+	if err != nil {
+		level.Error(a.logger).Log("msg", "reading value", "requestID", requestID, "err", err)
+		a.writeError(w, http.StatusInternalServerError, "reading value")
+		return
+	}
+	if val == nil {
+		a.writeError(w, http.StatusNotFound, "no value for request id: "+id)
+		return
+	}
+	a.writeJSON(w, val)
+}
+
+func (a *APIRouter) values(w http.ResponseWriter) {
+	ids := make([]int, 0, len(tracker.PSRs))
+	for id := range tracker.PSRs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	vals := make([]*ValueResponse, 0, len(ids))
+	for _, id := range ids {
+		val, err := a.readValue(id)
+		if err != nil {
+			level.Error(a.logger).Log("msg", "reading value", "requestID", id, "err", err)
+			a.writeError(w, http.StatusInternalServerError, "reading values")
+			return
+		}
+		if val != nil {
+			vals = append(vals, val)
+		}
+	}
+	a.writeJSON(w, vals)
+}
+
+func (a *APIRouter) readValue(requestID int) (*ValueResponse, error) {
+	data, err := a.db.Get(db.QueriedValuePrefix + strconv.Itoa(requestID))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	val, err := hexutil.DecodeBig(string(data))
+	if err != nil {
+		return nil, err
+	}
+	resp := &ValueResponse{RequestID: requestID, Value: val.String()}
+	data, err = a.db.Get(db.ValueRecordKey(uint64(requestID)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		rec, err := db.DecodeValueRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		resp.Time, resp.Confidence, resp.Sources = &rec.Time, rec.Confidence, rec.Sources
+	}
+	if psr, ok := tracker.PSRs[requestID]; ok {
+		resp.Granularity = psr.Granularity()
+		price, _ := new(big.Float).Quo(new(big.Float).SetInt(val), big.NewFloat(float64(resp.Granularity))).Float64()
+		resp.Price = price
+	}
+	return resp, nil
+}
+
+func (a *APIRouter) history(w http.ResponseWriter, req *http.Request, symbol string) {
+	apis, ok := tracker.GetIndexes()[symbol]
+	if !ok {
+		a.writeError(w, http.StatusNotFound, "unknown symbol: "+symbol)
+		return
+	}
+
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	var err error
+	if v := req.URL.Query().Get("to"); v != "" {
+		if to, err = parseTime(v); err != nil {
+			a.writeError(w, http.StatusBadRequest, "invalid to parameter: "+err.Error())
+			return
+		}
+	}
+	if v := req.URL.Query().Get("from"); v != "" {
+		if from, err = parseTime(v); err != nil {
+			a.writeError(w, http.StatusBadRequest, "invalid from parameter: "+err.Error())
+			return
+		}
+	}
+	if from.After(to) {
+		a.writeError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	resp := &HistoryResponse{
+		Symbol:  symbol,
+		From:    from,
+		To:      to,
+		Sources: make(map[string][]*apiOracle.PriceStamp),
+	}
+	for _, api := range apis {
+		vals := apiOracle.GetRequestValuesForTime(api.Identifier, to, to.Sub(from))
+		if vals == nil {
+			vals = []*apiOracle.PriceStamp{}
+		}
+		resp.Sources[api.Name] = append(resp.Sources[api.Name], vals...)
+	}
+	a.writeJSON(w, resp)
+}
+
+func (a *APIRouter) sources(w http.ResponseWriter) {
+	seen := make(map[*tracker.IndexTracker]bool)
+	var resp []*SourceResponse
+	for _, apis := range tracker.GetIndexes() {
+		for _, api := range apis {
+			if seen[api] {
+				continue
+			}
+			seen[api] = true
+			latest, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, time.Now())
+			resp = append(resp, &SourceResponse{
+				Name:    api.Name,
+				Symbols: api.Symbols,
+				Latest:  latest,
+				Health:  api.Health(time.Now()),
+			})
+		}
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].Name == resp[j].Name {
+			return strings.Join(resp[i].Symbols, ",") < strings.Join(resp[j].Symbols, ",")
+		}
+		return resp[i].Name < resp[j].Name
+	})
+	a.writeJSON(w, resp)
+}
+
+func (a *APIRouter) miners(w http.ResponseWriter) {
+	if a.registry == nil {
+		a.writeError(w, http.StatusNotFound, "the data server doesn't track miners")
+		return
+	}
+	miners, err := a.registry.Miners()
+	if err != nil {
+		level.Error(a.logger).Log("msg", "reading miners", "err", err)
+		a.writeError(w, http.StatusInternalServerError, "reading miners")
+		return
+	}
+	a.writeJSON(w, miners)
+}
+
+func (a *APIRouter) authorized(req *http.Request) bool {
+	if a.token == "" {
+		return true
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(a.token)) == 1
+}
+
+// allow reports whether the client is within its rate limit.
+// Idle clients are dropped to keep the limiters map from growing forever.
+func (a *APIRouter) allow(client string) bool {
+	if a.limit == rate.Inf {
+		return true
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	now := time.Now()
+	for id, l := range a.limiters {
+		if now.Sub(l.lastSeen) > limiterIdleTimeout {
+			delete(a.limiters, id)
+		}
+	}
+	l, ok := a.limiters[client]
+	if !ok {
+		l = &clientLimiter{limiter: rate.NewLimiter(a.limit, a.burst)}
+		a.limiters[client] = l
+	}
+	l.lastSeen = now
+	return l.limiter.AllowN(now, 1)
+}
+
+func (a *APIRouter) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		level.Error(a.logger).Log("msg", "writing the response", "err", err)
+	}
+}
+
+func (a *APIRouter) writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorResponse{Error: msg}); err != nil {
+		level.Error(a.logger).Log("msg", "writing the error response", "err", err)
+	}
+}
+
+// clientID identifies the client by its remote IP so that
+// all connections from the same host share a rate limit.
+func clientID(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// parseTime accepts either a unix timestamp or an RFC3339 formatted time.
+func parseTime(v string) (time.Time, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// APIClient reads the JSON API of a running data server.
+type APIClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewAPIClient creates a client for the API of the data server listening on the configured host and port.
+// It connects with the TLS config of the miners and sends the bearer token from the environment.
+func NewAPIClient(cfg *config.Config) (*APIClient, error) {
+	host := cfg.DataServer.ListenHost
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	scheme := "http://"
+	client := http.DefaultClient
+	tlsConfig, err := cfg.Mine.RemoteDBTLS.ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "remote DB TLS config")
+	}
+	if tlsConfig != nil {
+		scheme = "https://"
+		client = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	}
+	return &APIClient{
+		url:    fmt.Sprintf("%s%s:%d%s", scheme, host, cfg.DataServer.ListenPort, APIPrefix),
+		token:  os.Getenv(config.APITokenEnvName),
+		client: client,
+	}, nil
+}
+
+// Sources returns all sources of the data server with their health.
+func (c *APIClient) Sources(ctx context.Context) ([]*SourceResponse, error) {
+	var sources []*SourceResponse
+	if err := c.get(ctx, "sources", &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (c *APIClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating the request")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "requesting %v", path)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return errors.Errorf("requesting %v:%v", path, resp.Status)
+		}
+		return errors.Errorf("requesting %v:%v", path, e.Error)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrapf(err, "decoding %v", path)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+func createTestAPI(t *testing.T, cfg *config.Config) (*APIRouter, db.DB) {
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	_, err := tracker.BuildIndexTrackers(cfg, DB)
+	testutil.Ok(t, err)
+	logger := util.SetupLogger()("debug")
+	return CreateAPI(logger, cfg, DB, nil), DB
+}
+
+func TestAPIValues(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	api, DB := createTestAPI(t, cfg)
+
+	testutil.Ok(t, DB.Put(db.QueriedValuePrefix+"1", []byte(hexutil.EncodeBig(big.NewInt(1234560000)))))
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"values/1", nil))
+	testutil.Equals(t, http.StatusOK, rec.Code)
+	var val ValueResponse
+	testutil.Ok(t, json.NewDecoder(rec.Body).Decode(&val))
+	testutil.Equals(t, ValueResponse{RequestID: 1, Value: "1234560000", Granularity: 1000000, Price: 1234.56}, val)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"values/2", nil))
+	testutil.Equals(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"values/abc", nil))
+	testutil.Equals(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIHistory(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	api, _ := createTestAPI(t, cfg)
+
+	source := tracker.GetIndexes()["ETH/USD"][0]
+	now := time.Now()
+	apiOracle.SetRequestValue(source.Identifier, now.Add(-2*time.Hour), apiOracle.PriceInfo{Price: 100})
+	apiOracle.SetRequestValue(source.Identifier, now.Add(-time.Hour), apiOracle.PriceInfo{Price: 101})
+
+	req := httptest.NewRequest(http.MethodGet, APIPrefix+"history/ETH/USD", nil)
+	q := req.URL.Query()
+	q.Set("from", now.Add(-90*time.Minute).Format(time.RFC3339))
+	q.Set("to", now.Format(time.RFC3339))
+	req.URL.RawQuery = q.Encode()
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	testutil.Equals(t, http.StatusOK, rec.Code)
+	var hist HistoryResponse
+	testutil.Ok(t, json.NewDecoder(rec.Body).Decode(&hist))
+	testutil.Equals(t, "ETH/USD", hist.Symbol)
+	testutil.Equals(t, 1, len(hist.Sources[source.Name]))
+	testutil.Equals(t, 101.0, hist.Sources[source.Name][0].Price)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"history/NOPE", nil))
+	testutil.Equals(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIAuthAndRateLimit(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	os.Setenv(config.APITokenEnvName, "secret")
+	defer os.Unsetenv(config.APITokenEnvName)
+	cfg.DataServer.API.RateLimit = 1
+	cfg.DataServer.API.RateBurst = 2
+	api, _ := createTestAPI(t, cfg)
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"sources", nil))
+	testutil.Equals(t, http.StatusUnauthorized, rec.Code)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, APIPrefix+"sources", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		testutil.Equals(t, http.StatusOK, rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, APIPrefix+"sources", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	testutil.Equals(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestAPIMiners(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	api, _ := createTestAPI(t, cfg)
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"miners", nil))
+	testutil.Equals(t, http.StatusNotFound, rec.Code)
+
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	registry := db.NewMinerRegistry(DB, []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"})
+	registry.Seen("0x92f91500e105e3051f3cf94616831b58f6bce1e8")
+	api = CreateAPI(util.SetupLogger()("debug"), cfg, DB, registry)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"miners", nil))
+	testutil.Equals(t, http.StatusOK, rec.Code)
+	var miners []*db.MinerActivity
+	testutil.Ok(t, json.NewDecoder(rec.Body).Decode(&miners))
+	testutil.Equals(t, 1, len(miners))
+	testutil.Equals(t, "0x92f91500e105e3051f3cf94616831b58f6bce1e8", miners[0].Address)
+	testutil.Equals(t, uint64(1), miners[0].Requests)
+}
@@ -38,9 +38,11 @@ func (r *RemoteProxyRouter) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		fmt.Fprintf(w, "Cannot serve request")
 		return
 	}
-	data, err := ioutil.ReadAll(req.Body)
+	// Anything above the max request size would be rejected anyway so don't even read it.
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, db.MaxRequestSize))
 	if err != nil {
 		r.log.Error("Problem reading request data:%v", err)
+		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprint(w, "Could not read request data")
 		return
 	}
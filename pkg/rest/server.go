@@ -5,6 +5,7 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
@@ -23,8 +24,9 @@ type Server struct {
 }
 
 // Create a new server instance for the given host/port.
-func Create(ctx context.Context, proxy db.DataServerProxy, host string, port uint) (*Server, error) {
-	srv := &http.Server{Addr: fmt.Sprintf("%s:%d", host, port)}
+// The server uses TLS when a tls config is given.
+func Create(ctx context.Context, proxy db.DataServerProxy, host string, port uint, tlsConfig *tls.Config) (*Server, error) {
+	srv := &http.Server{Addr: fmt.Sprintf("%s:%d", host, port), TLSConfig: tlsConfig}
 
 	remoteHandler, err := CreateRemoteProxy(ctx, proxy)
 	if err != nil {
@@ -35,15 +37,26 @@ func Create(ctx context.Context, proxy db.DataServerProxy, host string, port uin
 	}
 
 	http.Handle("/", remoteHandler)
+	if streamer, ok := proxy.(db.DataStreamer); ok {
+		http.Handle(db.StreamPath, CreateStreamRouter(streamer))
+	}
 	return &Server{server: srv, dataProxy: proxy}, nil
 }
 
 // Start the server listening for incoming requests.
 func (s *Server) Start() {
 	go func() {
-		serverLog.Info("Starting server on %+v\n", s.server.Addr)
+		var err error
 		// returns ErrServerClosed on graceful close
-		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
+		if s.server.TLSConfig != nil {
+			serverLog.Info("Starting TLS server on %+v\n", s.server.Addr)
+			// The certificates are already loaded in the tls config.
+			err = s.server.ListenAndServeTLS("", "")
+		} else {
+			serverLog.Info("Starting server on %+v\n", s.server.Addr)
+			err = s.server.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
 			// NOTE: there is a chance that next line won't have time to run,
 			// as main() doesn't wait for this goroutine to stop. don't use
 			// code with race conditions like these for production. see post
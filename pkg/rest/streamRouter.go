@@ -0,0 +1,60 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+// StreamRouter handles the subscriptions of remote miners.
+type StreamRouter struct {
+	streamer db.DataStreamer
+	log      *util.Logger
+}
+
+// CreateStreamRouter creates a stream router instance.
+func CreateStreamRouter(streamer db.DataStreamer) *StreamRouter {
+	return &StreamRouter{streamer: streamer, log: util.NewLogger("rest", "StreamRouter")}
+}
+
+func (r *StreamRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, db.MaxRequestSize))
+	if err != nil {
+		r.log.Error("Problem reading stream request data:%v", err)
+		http.Error(w, "Could not read request data", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	if err := r.streamer.IncomingStream(req.Context(), data, &flushWriter{w: w, flusher: flusher}); err != nil {
+		r.log.Error("Problem streaming to a remote miner:%v", err)
+	}
+}
+
+// flushWriter sends every write to the client straight away.
+type flushWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flusher.Flush()
+	return n, err
+}
@@ -0,0 +1,105 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestStream(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	testutil.Ok(t, DB.Put(db.RequestIdKey, []byte("1")))
+
+	server, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	// Only the stream is served so any lookup that isn't streamed fails.
+	mux := http.NewServeMux()
+	mux.Handle(db.StreamPath, CreateStreamRouter(server.(db.DataStreamer)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	cfg.Mine.RemoteDBHost = host
+	cfg.Mine.RemoteDBPort = uint(p)
+	client, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := client.(db.DataStreamer).Stream(ctx, []string{db.RequestIdKey, db.CurrentChallengeKey})
+
+	waitUpdate := func() {
+		select {
+		case <-updates:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no update from the stream")
+		}
+	}
+
+	waitUpdate()
+	vals, err := client.BatchGet([]string{db.RequestIdKey, db.CurrentChallengeKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string][]byte{db.RequestIdKey: []byte("1")}, vals)
+
+	testutil.Ok(t, DB.Put(db.CurrentChallengeKey, []byte("challenge")))
+	waitUpdate()
+	vals, err = client.BatchGet([]string{db.RequestIdKey, db.CurrentChallengeKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string][]byte{db.RequestIdKey: []byte("1"), db.CurrentChallengeKey: []byte("challenge")}, vals)
+}
+
+func TestStreamRejectedKey(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	testutil.Ok(t, DB.Put(db.RequestIdKey, []byte("1")))
+
+	server, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	mux := http.NewServeMux()
+	mux.Handle(db.StreamPath, CreateStreamRouter(server.(db.DataStreamer)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	cfg.Mine.RemoteDBHost = host
+	cfg.Mine.RemoteDBPort = uint(p)
+	client, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+
+	// Keys the data server doesn't serve are left out of the stream.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := client.(db.DataStreamer).Stream(ctx, []string{db.RequestIdKey, "unknown"})
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update from the stream")
+	}
+	vals, err := client.BatchGet([]string{db.RequestIdKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string][]byte{db.RequestIdKey: []byte("1")}, vals)
+}
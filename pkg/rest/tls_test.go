@@ -0,0 +1,168 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/phayes/freeport"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// createTestCert writes a certificate and its key to the given dir.
+// When parent is nil the certificate is a self-signed CA.
+func createTestCert(t *testing.T, dir, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.Ok(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	testutil.Ok(t, err)
+	cert, err := x509.ParseCertificate(der)
+	testutil.Ok(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	testutil.Ok(t, err)
+
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	testutil.Ok(t, ioutil.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	testutil.Ok(t, ioutil.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return c
+}
+
+func TestMutualTLS(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+
+	dir := t.TempDir()
+	ca := createTestCert(t, dir, "ca", nil)
+	server := createTestCert(t, dir, "server", ca)
+	client := createTestCert(t, dir, "client", ca)
+	otherCA := createTestCert(t, dir, "other-ca", nil)
+	other := createTestCert(t, dir, "other", otherCA)
+
+	port, err := freeport.GetFreePort()
+	testutil.Ok(t, err)
+	cfg.DataServer.ListenHost = "localhost"
+	cfg.DataServer.ListenPort = uint(port)
+	cfg.DataServer.TLS = config.TLS{CertFile: server.certFile, KeyFile: server.keyFile, CAFile: ca.certFile}
+	cfg.Mine.RemoteDBHost = "localhost"
+	cfg.Mine.RemoteDBPort = uint(port)
+	cfg.Mine.RemoteDBTLS = config.TLS{CertFile: client.certFile, KeyFile: client.keyFile, CAFile: ca.certFile}
+
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	testutil.Ok(t, DB.Put(db.RequestIdKey, []byte("1")))
+
+	proxy, err := db.OpenRemoteDB(DB)
+	testutil.Ok(t, err)
+	tlsConfig, err := cfg.DataServer.TLS.ServerConfig()
+	testutil.Ok(t, err)
+	srv, err := Create(context.Background(), proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort, tlsConfig)
+	testutil.Ok(t, err)
+	srv.Start()
+	defer func() {
+		testutil.Ok(t, srv.Stop())
+	}()
+
+	// A miner with a certificate signed by the pinned CA.
+	val, err := proxy.Get(db.RequestIdKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte("1"), val)
+
+	url := "https://localhost:" + strconv.Itoa(port)
+	post := func(tlsCfg config.TLS) error {
+		clientConfig, err := tlsCfg.ClientConfig()
+		testutil.Ok(t, err)
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		resp, err := c.Post(url, "application/octet-stream", bytes.NewReader(nil))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+
+	// Miners without a client certificate or with one signed by another CA are rejected.
+	testutil.NotOk(t, post(config.TLS{CAFile: ca.certFile}))
+	testutil.NotOk(t, post(config.TLS{CertFile: other.certFile, KeyFile: other.keyFile, CAFile: ca.certFile}))
+	// Servers with a certificate not signed by the pinned CA are rejected.
+	testutil.NotOk(t, post(config.TLS{CertFile: client.certFile, KeyFile: client.keyFile, CAFile: otherCA.certFile}))
+
+	// Plain HTTP isn't served.
+	resp, err := http.Post("http://localhost:"+strconv.Itoa(port), "application/octet-stream", bytes.NewReader(nil))
+	if err == nil {
+		resp.Body.Close()
+		testutil.Equals(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca := createTestCert(t, dir, "ca", nil)
+
+	cfg, err := config.TLS{}.ServerConfig()
+	testutil.Ok(t, err)
+	testutil.Assert(t, cfg == nil, "expected no tls config when TLS is not enabled")
+
+	_, err = config.TLS{CAFile: ca.certFile}.ServerConfig()
+	testutil.NotOk(t, err, "a server requires a certificate")
+
+	_, err = config.TLS{CAFile: filepath.Join(dir, "missing.crt")}.ClientConfig()
+	testutil.NotOk(t, err)
+
+	_, err = config.TLS{CAFile: ca.keyFile}.ClientConfig()
+	testutil.NotOk(t, err, "a key isn't a valid CA")
+
+	clientCfg, err := config.TLS{CAFile: ca.certFile}.ClientConfig()
+	testutil.Ok(t, err)
+	testutil.Assert(t, clientCfg.MinVersion == tls.VersionTLS12, "expected TLS 1.2 or newer")
+}
@@ -4,6 +4,7 @@
 package tracker
 
 import (
+	"math"
 	"time"
 
 	"github.com/tellor-io/telliot/pkg/apiOracle"
@@ -32,53 +33,63 @@ func (s *Ampl) Granularity() int64 {
 	return int64(s.granularity)
 }
 
-// compute the average ampl price over a 24 hour period using a chained price feed.
+func (s *Ampl) Class() string {
+	return ClassAverage
+}
+
+// AmpleChained computes the average AMPL price over the 24 hours before the last 2am UTC fixing
+// using a chained price feed. The confidence is averaged over the 10 minute buckets of the day:
+// a bucket has the lower confidence of the chained price and the AMPL price, both computed with
+// the confidence model, and buckets without both prices have none, so like the time weighted
+// averages the coverage includes the fraction of the interval with values.
+// The dispersion is averaged over the buckets with values like the price, so that sources
+// disagreeing for a short time don't count more than they move the average.
 func AmpleChained(chainedPair string) IndexProcessor {
-	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
 
-		eod := clck.Now().UTC()
-		d := 24 * time.Hour
-		eod = eod.Truncate(d)
-		eod = eod.Add(2 * time.Hour)
+		eod := Fixing{TimeOfDay: 2 * time.Hour, Location: time.UTC}.Last(at)
 
 		//Get the value always at 2am UTC
 		//time weight individual 10 minute buckets
 		//VWAP based on time at 2am
+		const buckets = 144
 		numVals := 0
 		sum := 0.0
 		maxVolume := 0.0
+		confidence := 0.0
+		dispersionSum := 0.0
 
 		interval := 10 * time.Minute
 
 		//function to collect API values over an interval
 		apiFn := VolumeWeightedAPIs(TimeWeightedAvg(interval, NoDecay))
 
-		for i := 0; i < 144; i++ {
+		for i := 0; i < buckets; i++ {
 			thisTime := eod.Add(time.Duration(-i) * interval)
-			chainedPrice, confidence := MedianAt(indexes[chainedPair], thisTime)
-			if confidence < 0.01 {
-				//we don't have an accurate estimate of the intermediary price, so we can't convert the AMPL price to USD
+			chainedPrice, chainedConfidence, chainedDispersion := MedianAt(GetIndexes()[chainedPair], thisTime)
+			if chainedConfidence == 0 {
+				//we don't have an estimate of the intermediary price, so we can't convert the AMPL price to USD
 				continue
 			}
-			avg, confidence := apiFn(apis, thisTime)
-			if confidence < 0.01 {
-				//our estimate of AMPL/intermediary is not good enough right now
+			avg, avgConfidence, avgDispersion := apiFn(apis, thisTime)
+			if avgConfidence == 0 {
+				//we don't have an estimate of AMPL/intermediary right now
 				continue
 			}
 			sum += avg.Price * chainedPrice.Price
 			if avg.Volume > maxVolume {
 				maxVolume = avg.Volume
 			}
+			confidence += math.Min(chainedConfidence, avgConfidence)
+			dispersionSum += math.Max(chainedDispersion, avgDispersion)
 			numVals++
 		}
+		if numVals == 0 || sum <= 0 {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
 		var result apiOracle.PriceInfo
 		result.Price = sum / float64(numVals)
 		result.Volume = maxVolume
-		if sum > 0 {
-			return result, float64(numVals) / 144.0
-
-		} else {
-			return result, 0
-		}
+		return result, confidence / buckets, dispersionSum / float64(numVals)
 	}
 }
@@ -20,14 +20,17 @@ import (
 )
 
 func TestAmpl(t *testing.T) {
-	util.CreateTestClient(&client, mockAPI)
+	transport = util.RoundTripFunc(mockAPI)
 	cfg := config.OpenTestConfig(t)
 	DB, cleanup := db.OpenTestDB(t)
 	defer t.Cleanup(cleanup)
 
 	mock := clock.NewMock()
 	clck = mock
-	mock.Set(time.Now())
+	// Start just before the day averaged for the last fixing and poll often enough
+	// that the values span every 10 minute bucket whatever the time of the test.
+	fixing := Fixing{TimeOfDay: 2 * time.Hour, Location: time.UTC}.Last(time.Now())
+	mock.Set(fixing.Add(-24*time.Hour - 10*time.Minute))
 	if _, err := BuildIndexTrackers(cfg, DB); err != nil {
 		testutil.Ok(t, err)
 	}
@@ -39,17 +42,23 @@ func TestAmpl(t *testing.T) {
 	indexers = append(indexers, amplTrackers...)
 	indexers = append(indexers, btcTrackers...)
 	indexers = append(indexers, amplBtcTrackers...)
-	for i := 0; i < 288; i++ {
+	for i := 0; i < 292; i++ {
 		for _, indexer := range indexers {
 			if err := indexer.Exec(context.Background()); err != nil {
 				testutil.Ok(t, err)
 			}
 		}
-		mock.Add(10 * time.Minute)
+		mock.Add(5 * time.Minute)
 	}
+	// The value of the last day is computed with the same confidence model as the other request IDs.
+	val, conf, dispersion := PSRValueForTime(10, mock.Now())
+	testutil.Assert(t, val > 0, "expected a value")
+	testutil.Assert(t, conf >= cfg.MinConfidence && conf <= 1, "unexpected confidence:%v", conf)
+	// The mocked AMPL/USD sources are about 2% apart.
+	testutil.Assert(t, dispersion > 0 && dispersion < 0.05, "unexpected dispersion:%v", dispersion)
 
 	// reset mocks
-	client = http.Client{}
+	transport = nil
 	clck = clock.New()
 }
 
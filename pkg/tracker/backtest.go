@@ -0,0 +1,192 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+)
+
+// BacktestEvent is a value of a request ID from an on-chain event,
+// the accepted value of a NewValue event or the value a miner submitted in a NonceSubmitted event.
+type BacktestEvent struct {
+	Event     string `json:"event"`
+	Block     uint64 `json:"block"`
+	Timestamp int64  `json:"timestamp"`
+	RequestID int    `json:"requestId"`
+	// Value is the decimal on-chain value.
+	Value string `json:"value"`
+	Miner string `json:"miner,omitempty"`
+
+	value *big.Int
+}
+
+func (e *BacktestEvent) time() time.Time {
+	return time.Unix(e.Timestamp, 0)
+}
+
+// ReadBacktestEvents reads a JSON array of exported events sorted by their block time.
+func ReadBacktestEvents(path string) ([]*BacktestEvent, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read events file:%v", path)
+	}
+	var events []*BacktestEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, errors.Wrapf(err, "decoding events file:%v", path)
+	}
+	for i, e := range events {
+		v, ok := new(big.Int).SetString(e.Value, 10)
+		if !ok {
+			return nil, errors.Errorf("invalid value of event %v:%v", i, e.Value)
+		}
+		e.value = v
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
+	return events, nil
+}
+
+// BacktestResult compares the values of the PSR of a request ID with the values of one event type.
+type BacktestResult struct {
+	RequestID int
+	Event     string
+	Events    int
+	// Missing are the events without a PSR value of at least minConfidence at their time.
+	Missing int
+	// MeanError is the average relative difference of the PSR values from the on-chain values,
+	// positive when the PSR values are higher.
+	MeanError float64
+	// P50, P90, P99 and Max are percentiles of the absolute relative difference.
+	P50, P90, P99, Max float64
+	// Outside are the events with a relative difference above disputeThreshold.
+	Outside int
+
+	errors []float64
+}
+
+// backtest computes the PSR values at the times of the events in order.
+type backtest struct {
+	cfg     *config.Config
+	events  []*BacktestEvent
+	next    int
+	results map[backtestKey]*BacktestResult
+}
+
+type backtestKey struct {
+	event     string
+	requestID int
+}
+
+// until computes the PSR values of the events before t, all the remaining events for the zero time.
+func (b *backtest) until(t time.Time) {
+	for ; b.next < len(b.events); b.next++ {
+		e := b.events[b.next]
+		if !t.IsZero() && !e.time().Before(t) {
+			return
+		}
+		key := backtestKey{event: e.Event, requestID: e.RequestID}
+		r, ok := b.results[key]
+		if !ok {
+			r = &BacktestResult{RequestID: e.RequestID, Event: e.Event}
+			b.results[key] = r
+		}
+		r.Events++
+		onChain, _ := new(big.Float).SetInt(e.value).Float64()
+		if _, ok := PSRs[e.RequestID]; !ok || onChain == 0 {
+			r.Missing++
+			continue
+		}
+		val, conf, _ := PSRValueForTime(e.RequestID, e.time())
+		if conf < b.cfg.MinConfidence || math.IsNaN(val) {
+			r.Missing++
+			continue
+		}
+		r.errors = append(r.errors, (val-onChain)/onChain)
+	}
+}
+
+func (b *backtest) summary() []*BacktestResult {
+	var results []*BacktestResult
+	for _, r := range b.results {
+		abs := make([]float64, len(r.errors))
+		for i, e := range r.errors {
+			r.MeanError += e / float64(len(r.errors))
+			abs[i] = math.Abs(e)
+			if abs[i] > b.cfg.DisputeThreshold {
+				r.Outside++
+			}
+		}
+		sort.Float64s(abs)
+		r.P50, r.P90, r.P99 = percentile(abs, 0.5), percentile(abs, 0.9), percentile(abs, 0.99)
+		if len(abs) > 0 {
+			r.Max = abs[len(abs)-1]
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].RequestID != results[j].RequestID {
+			return results[i].RequestID < results[j].RequestID
+		}
+		return results[i].Event < results[j].Event
+	})
+	return results
+}
+
+// percentile returns the nearest rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// BacktestArchive replays an archive recorded with recordFile like Replay
+// and computes the PSR values at the time of every event while the archive is replayed,
+// so that they use the sources that were healthy then.
+func BacktestArchive(ctx context.Context, cfg *config.Config, DB db.DB, archive string, events []*BacktestEvent) ([]*BacktestResult, error) {
+	b := &backtest{cfg: cfg, events: events, results: make(map[backtestKey]*BacktestResult)}
+	if _, err := replay(ctx, cfg, DB, archive, b.until); err != nil {
+		return nil, err
+	}
+	return b.summary(), nil
+}
+
+// BacktestSaved computes the PSR values at the time of every event from the value history
+// that the trackers save to saved.json, with all the sources of the index file healthy.
+func BacktestSaved(cfg *config.Config, saved string, events []*BacktestEvent) ([]*BacktestResult, error) {
+	_, symbolIndexes, err := parseIndexFile(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPSRs(symbolIndexes); err != nil {
+		return nil, errors.Wrap(err, "checking the PSRs")
+	}
+	mock := clock.NewMock()
+	if len(events) > 0 {
+		mock.Set(events[len(events)-1].time())
+	}
+	if err := apiOracle.LoadValueHistory(saved, mock); err != nil {
+		return nil, err
+	}
+	setIndexes(symbolIndexes)
+
+	b := &backtest{cfg: cfg, events: events, results: make(map[backtestKey]*BacktestResult)}
+	b.until(time.Time{})
+	return b.summary(), nil
+}
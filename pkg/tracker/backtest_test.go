@@ -0,0 +1,120 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestBacktest(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	defer func(old map[int]ValueGenerator) { PSRs = old }(PSRs)
+	PSRs = map[int]ValueGenerator{
+		1: &SingleSymbol{symbol: "ETH/USD", granularity: 1000, transform: MedianAt},
+	}
+	defer func(old map[string][]*IndexTracker) { setIndexes(old) }(GetIndexes())
+	defer func(c clock.Clock) {
+		clck = c
+		apiOracle.InitMemoryValueOracle(c)
+	}(clck)
+	defer func(threshold float64) { cfg.DisputeThreshold = threshold }(cfg.DisputeThreshold)
+	cfg.DisputeThreshold = 0.01
+
+	dir, err := ioutil.TempDir("", "backtest")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+	defer func(folder string) { cfg.ConfigFolder = folder }(cfg.ConfigFolder)
+	cfg.ConfigFolder = dir
+	writeJSON := func(name string, v interface{}) string {
+		data, err := json.Marshal(v)
+		testutil.Ok(t, err)
+		path := filepath.Join(dir, name)
+		testutil.Ok(t, ioutil.WriteFile(path, data, 0644))
+		return path
+	}
+
+	ids := []string{"https://a.example.com/price", "https://b.example.com/price", "https://c.example.com/price"}
+	var sources []map[string]string
+	for _, id := range ids {
+		sources = append(sources, map[string]string{"URL": id, "param": "$.price"})
+	}
+	writeJSON("indexes.json", map[string][]map[string]string{"ETH/USD": sources})
+
+	// The sources report 100, 101 and 102 every 10 minutes for 2 hours.
+	start := time.Date(2021, 1, 29, 0, 0, 0, 0, time.UTC)
+	archive, err := os.Create(filepath.Join(dir, "archive.jsonl"))
+	testutil.Ok(t, err)
+	saved := make(map[string][]*apiOracle.PriceStamp)
+	enc := json.NewEncoder(archive)
+	for ts := start; ts.Before(start.Add(2 * time.Hour)); ts = ts.Add(10 * time.Minute) {
+		for i, id := range ids {
+			price := 100 + float64(i)
+			testutil.Ok(t, enc.Encode(&archiveEntry{ID: id, Time: ts, Payload: fmt.Sprintf(`{"price": %v}`, price)}))
+			saved[id] = append(saved[id], &apiOracle.PriceStamp{Created: ts, PriceInfo: apiOracle.PriceInfo{Price: price}})
+		}
+	}
+	testutil.Ok(t, archive.Close())
+	savedPath := writeJSON("saved.json", saved)
+
+	eventsPath := writeJSON("events.json", []map[string]interface{}{
+		{"event": "NewValue", "timestamp": start.Add(time.Hour).Unix(), "requestId": 1, "value": "96190"},
+		{"event": "NewValue", "timestamp": start.Add(30 * time.Minute).Unix(), "requestId": 1, "value": "101000"},
+		{"event": "NonceSubmitted", "timestamp": start.Add(time.Hour).Unix(), "requestId": 1, "value": "101000"},
+		// No values yet.
+		{"event": "NewValue", "timestamp": start.Add(-time.Hour).Unix(), "requestId": 1, "value": "101000"},
+		// No PSR.
+		{"event": "NewValue", "timestamp": start.Add(time.Hour).Unix(), "requestId": 99, "value": "1"},
+	})
+	events, err := ReadBacktestEvents(eventsPath)
+	testutil.Ok(t, err)
+	testutil.Equals(t, start.Add(-time.Hour).Unix(), events[0].Timestamp)
+
+	check := func(results []*BacktestResult) {
+		testutil.Equals(t, 3, len(results))
+
+		newValue := results[0]
+		testutil.Equals(t, 1, newValue.RequestID)
+		testutil.Equals(t, "NewValue", newValue.Event)
+		testutil.Equals(t, 3, newValue.Events)
+		testutil.Equals(t, 1, newValue.Missing)
+		testutil.Equals(t, 1, newValue.Outside)
+		expected := (101000 - 96190) / 96190.0
+		testutil.Assert(t, math.Abs(newValue.Max-expected) < 1e-9, "unexpected max error:%v", newValue.Max)
+		testutil.Assert(t, math.Abs(newValue.MeanError-expected/2) < 1e-9, "unexpected mean error:%v", newValue.MeanError)
+		testutil.Equals(t, 0.0, newValue.P50)
+
+		testutil.Equals(t, "NonceSubmitted", results[1].Event)
+		testutil.Equals(t, 0, results[1].Outside)
+		testutil.Equals(t, 0.0, results[1].Max)
+
+		testutil.Equals(t, 99, results[2].RequestID)
+		testutil.Equals(t, 1, results[2].Missing)
+	}
+
+	results, err := BacktestArchive(context.Background(), cfg, DB, filepath.Join(dir, "archive.jsonl"), events)
+	testutil.Ok(t, err)
+	check(results)
+
+	events, err = ReadBacktestEvents(eventsPath)
+	testutil.Ok(t, err)
+	results, err = BacktestSaved(cfg, savedPath, events)
+	testutil.Ok(t, err)
+	check(results)
+}
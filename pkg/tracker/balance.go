@@ -11,6 +11,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
@@ -18,6 +19,7 @@ import (
 const BalanceTrackerName = "BalanceTracker"
 
 type BalanceTracker struct {
+	config  *config.Config
 	db      db.DB
 	client  rpc.ETHClient
 	account *rpc.Account
@@ -28,8 +30,9 @@ func (b *BalanceTracker) String() string {
 	return BalanceTrackerName
 }
 
-func NewBalanceTracker(logger log.Logger, db db.DB, client rpc.ETHClient, account *rpc.Account) *BalanceTracker {
+func NewBalanceTracker(logger log.Logger, config *config.Config, db db.DB, client rpc.ETHClient, account *rpc.Account) *BalanceTracker {
 	return &BalanceTracker{
+		config:  config,
 		db:      db,
 		client:  client,
 		account: account,
@@ -38,16 +41,31 @@ func NewBalanceTracker(logger log.Logger, db db.DB, client rpc.ETHClient, accoun
 }
 
 func (b *BalanceTracker) Exec(ctx context.Context) error {
-
-	_fromAddress := b.account.Address
-
-	balance, err := b.client.BalanceAt(ctx, _fromAddress, nil)
-
+	// Every miner gets its own balance, the first one is the data server account.
+	addrs, err := minerAddresses(b.config, b.db, b.account)
 	if err != nil {
-		return errors.Wrap(err, "getting balance")
+		return errors.Wrap(err, "getting the whitelisted miners")
 	}
-	level.Info(b.logger).Log("msg", "got balance", "balance", fmt.Sprintf("%.2e", float64(balance.Int64())))
-
-	enc := hexutil.EncodeBig(balance)
-	return b.db.Put(db.BalanceKey, []byte(enc))
+	for _, addr := range addrs {
+		balance, err := b.client.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			if addr == b.account.Address {
+				return errors.Wrap(err, "getting balance")
+			}
+			level.Error(b.logger).Log("msg", "getting balance for miner", "address", addr.Hex(), "err", err)
+			continue
+		}
+		enc := []byte(hexutil.EncodeBig(balance))
+		if addr == b.account.Address {
+			level.Info(b.logger).Log("msg", "got balance", "balance", fmt.Sprintf("%.2e", float64(balance.Int64())))
+			// The legacy key is the balance of the data server account.
+			if err := b.db.Put(db.BalanceKey, enc); err != nil {
+				return errors.Wrap(err, "storing balance")
+			}
+		}
+		if err := b.db.Put(db.MinerKey(addr.Hex(), db.BalanceKey), enc); err != nil {
+			return errors.Wrap(err, "storing miner balance")
+		}
+	}
+	return nil
 }
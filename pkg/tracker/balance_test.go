@@ -25,7 +25,7 @@ func TestStringId(t *testing.T) {
 	defer t.Cleanup(cleanup)
 	logSetup := util.SetupLogger()
 	logger := logSetup("debug")
-	tracker := NewBalanceTracker(logger, DB, client, nil)
+	tracker := NewBalanceTracker(logger, nil, DB, client, nil)
 	res := tracker.String()
 
 	testutil.Equals(t, res, BalanceTrackerName, "didn't return expected string", BalanceTrackerName)
@@ -55,7 +55,7 @@ func TestNegativeBalance(t *testing.T) {
 	logger := logSetup("debug")
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewBalanceTracker(logger, DB, client, &account)
+	tracker := NewBalanceTracker(logger, cfg, DB, client, &account)
 	err = tracker.Exec(context.Background())
 	testutil.NotOk(t, err, "should have error")
 }
@@ -72,7 +72,7 @@ func dbBalanceTest(startBal *big.Int, t *testing.T) {
 	logger := logSetup("debug")
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewBalanceTracker(logger, DB, client, &account)
+	tracker := NewBalanceTracker(logger, cfg, DB, client, &account)
 	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
 	v, err := DB.Get(db.BalanceKey)
@@ -85,3 +85,27 @@ func dbBalanceTest(startBal *big.Int, t *testing.T) {
 	}
 	DB.Close()
 }
+
+func TestMinerBalances(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	other := "0x053b09e98ede40997546e8bb812cd838f18bb146"
+	cfg.ServerWhitelist = []string{other}
+	startBal := big.NewInt(356000)
+	opts := &rpc.MockOptions{ETHBalance: startBal, Nonce: 1, GasPrice: big.NewInt(700000000),
+		TokenBalance: big.NewInt(0), Top50Requests: []*big.Int{}}
+	client := rpc.NewMockClientWithValues(opts)
+
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	logger := util.SetupLogger()("debug")
+	account, err := rpc.NewAccount(cfg)
+	testutil.Ok(t, err)
+	tracker := NewBalanceTracker(logger, cfg, DB, client, &account)
+	testutil.Ok(t, tracker.Exec(context.Background()))
+
+	for _, addr := range []string{account.Address.Hex(), other} {
+		v, err := DB.Get(db.MinerKey(addr, db.BalanceKey))
+		testutil.Ok(t, err)
+		testutil.Equals(t, hexutil.EncodeBig(startBal), string(v))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"math"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+)
+
+// The confidence of a value computed from the values of several sources is a number between 0 and 1
+// that all index processors compute the same way, so that a confidence threshold means the same
+// thing for every request ID. It is the product of:
+//  - Freshness, the average freshness of the source values, see Freshness.
+//  - Coverage, the fraction of the configured sources that contributed a value.
+//    Quarantined sources and rejected outliers don't contribute.
+//  - Agreement, 1 minus the largest relative deviation of a contributing value from their median.
+//    Values deviating by 100% or more leave no confidence.
+// For example 3 fresh values from 4 configured sources that are all within 2% of their median
+// have a confidence of 1 * 0.75 * 0.98 = 0.735.
+
+// _freshAge is how old a value can be before it lowers the confidence.
+const _freshAge = 5 * time.Minute
+
+// Freshness is 1 for values up to 5 minutes old and decreases in proportion to the age after that,
+// i.e. 0.5 for a value 10 minutes old.
+func Freshness(age time.Duration) float64 {
+	if age <= _freshAge {
+		return 1
+	}
+	return float64(_freshAge) / float64(age)
+}
+
+// Coverage is the fraction of the configured sources that contributed a value.
+func Coverage(contributing, configured int) float64 {
+	if configured == 0 {
+		return 0
+	}
+	return math.Min(float64(contributing)/float64(configured), 1)
+}
+
+// Agreement is 1 minus the largest relative deviation of the values from their median, not lower than 0.
+func Agreement(values []apiOracle.PriceInfo) float64 {
+	return 1 - math.Min(dispersion(values), 1)
+}
+
+// Confidence combines the freshness, coverage and agreement of a value.
+func Confidence(freshness, coverage, agreement float64) float64 {
+	return freshness * coverage * agreement
+}
+
+// dispersion returns the largest relative deviation of the values from their median.
+func dispersion(values []apiOracle.PriceInfo) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	// Median sorts the values so sort a copy to keep the order of the caller.
+	median := Median(append([]apiOracle.PriceInfo(nil), values...)).Price
+	if median == 0 {
+		return 0
+	}
+	var max float64
+	for _, v := range values {
+		if d := math.Abs(v.Price-median) / math.Abs(median); d > max {
+			max = d
+		}
+	}
+	return max
+}
@@ -0,0 +1,58 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestConfidence(t *testing.T) {
+	testutil.Equals(t, 1.0, Freshness(time.Minute))
+	testutil.Equals(t, 0.5, Freshness(10*time.Minute))
+	testutil.Equals(t, 0.75, Coverage(3, 4))
+	testutil.Equals(t, 0.0, Coverage(0, 0))
+	testutil.Equals(t, 0.98, Agreement(prices(100, 98, 101)))
+	testutil.Equals(t, 0.0, Agreement(prices(100, 300, 101)))
+	testutil.Equals(t, 1.0, Agreement(prices(100)))
+
+	now := clck.Now()
+	apis := fixtureAPIs(t, now, prices(100, 98, 101)...)
+	// A configured source without a value lowers the coverage.
+	apis = append(apis, &IndexTracker{Identifier: t.Name() + "-missing"})
+	_, conf, _ := MedianAt(apis, now)
+	testutil.Equals(t, Confidence(1, 0.75, 0.98), conf)
+
+	// Old values lower the freshness.
+	old := &IndexTracker{Identifier: t.Name() + "-old"}
+	apiOracle.SetRequestValue(old.Identifier, now.Add(-10*time.Minute), apiOracle.PriceInfo{Price: 100})
+	_, conf, _ = MedianAt(append(apis[:3:3], old), now)
+	testutil.Equals(t, Confidence(0.875, 1, 0.98), conf)
+}
+
+func TestTimeWeightedAvgConfidence(t *testing.T) {
+	now := clck.Now()
+	full := &IndexTracker{Identifier: t.Name() + "-full"}
+	half := &IndexTracker{Identifier: t.Name() + "-half"}
+	for _, ago := range []int{59, 50, 40, 30, 20, 10, 0} {
+		apiOracle.SetRequestValue(full.Identifier, now.Add(-time.Duration(ago)*time.Minute), apiOracle.PriceInfo{Price: 100})
+	}
+	for _, ago := range []int{30, 20, 10, 0} {
+		apiOracle.SetRequestValue(half.Identifier, now.Add(-time.Duration(ago)*time.Minute), apiOracle.PriceInfo{Price: 100})
+	}
+
+	// The confidence doesn't depend on how often the sources are queried, only on the span of their values.
+	val, conf, _ := TimeWeightedAvg(time.Hour, NoDecay)([]*IndexTracker{full}, now)
+	testutil.Equals(t, 100.0, val.Price)
+	testutil.Assert(t, conf > 0.98, "unexpected confidence:%v", conf)
+
+	_, conf, _ = TimeWeightedAvg(time.Hour, NoDecay)([]*IndexTracker{full, half}, now)
+	testutil.Assert(t, conf > 0.74 && conf < 0.75, "unexpected confidence:%v", conf)
+
+	_, conf, _ = TimeWeightedAvg(time.Hour, NoDecay)([]*IndexTracker{{Identifier: t.Name() + "-none"}}, now)
+	testutil.Equals(t, 0.0, conf)
+}
@@ -42,6 +42,8 @@ type ValueCheckResult struct {
 	High, Low   float64
 	WithinRange bool
 	Datapoints  []float64
+	// Dispersions are the dispersions of the source values of the datapoints.
+	Dispersions []float64
 	Times       []time.Time
 }
 
@@ -49,13 +51,14 @@ type ValueCheckResult struct {
 func CheckValueAtTime(cfg *config.Config, reqID uint64, val *big.Int, at time.Time) *ValueCheckResult {
 
 	// check the value in 5 places, spread over cfg.DisputeTimeDelta.Duration.
-	var datapoints []float64
+	var datapoints, dispersions []float64
 	var times []time.Time
 	for i := 0; i < 5; i++ {
 		t := at.Add((time.Duration(i) - 2) * cfg.DisputeTimeDelta.Duration / 5)
-		fval, confidence := PSRValueForTime(int(reqID), t)
-		if confidence > 0.8 {
+		fval, confidence, dispersion := PSRValueForTime(int(reqID), t)
+		if confidence >= cfg.DisputeMinConfidence {
 			datapoints = append(datapoints, fval)
+			dispersions = append(dispersions, dispersion)
 			times = append(times, t)
 		}
 	}
@@ -89,6 +92,7 @@ func CheckValueAtTime(cfg *config.Config, reqID uint64, val *big.Int, at time.Ti
 		High:        max,
 		WithinRange: withinRange,
 		Datapoints:  datapoints,
+		Dispersions: dispersions,
 		Times:       times,
 	}
 }
@@ -172,7 +176,7 @@ func (c *disputeChecker) Exec(ctx context.Context) error {
 			if !result.WithinRange {
 				s := fmt.Sprintf("suspected incorrect value for requestID %d at %s:\n , nearest values:\n", reqID, blockTime)
 				for i, pt := range result.Datapoints {
-					s += fmt.Sprintf("\t%.0f, ", pt)
+					s += fmt.Sprintf("\t%.0f (sources within %.2f%%), ", pt, result.Dispersions[i]*100)
 					delta := blockTime.Sub(result.Times[i])
 					if delta > 0 {
 						s += fmt.Sprintf("%s before\n", delta.String())
@@ -5,11 +5,7 @@ package tracker
 
 import (
 	"context"
-	"fmt"
 
-	"strings"
-
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -62,17 +58,20 @@ func (b *DisputeTracker) Exec(ctx context.Context) error {
 	//testutil.Ok(t, errors.New(fmt.Spintf("Miner is not able to mine with status %v. Stopping all mining immediately", status)))
 	// }
 
-	//add all whitelisted miner addresses as well since they will be coming in
-	//asking for dispute status
-	for _, addr := range b.config.ServerWhitelist {
-		address := common.HexToAddress(addr)
+	// Add the data server account and all whitelisted miner addresses as well
+	// since they will be coming in asking for their own dispute status.
+	addrs, err := minerAddresses(b.config, b.db, b.account)
+	if err != nil {
+		return errors.Wrap(err, "getting the whitelisted miners")
+	}
+	for _, address := range addrs {
 		status, _, err := b.contract.Getter.GetStakerInfo(nil, address)
 		if err != nil {
-			level.Error(b.logger).Log("msg", "getting staker dispute status for miner", "address", addr, "err", err)
+			level.Error(b.logger).Log("msg", "getting staker dispute status for miner", "address", address.Hex(), "err", err)
+			continue
 		}
-		level.Info(b.logger).Log("msg", "whitelisted miner", "address", addr, "status", status)
-		dbKey := fmt.Sprintf("%s-%s", strings.ToLower(address.Hex()), db.DisputeStatusKey)
-		err = b.db.Put(dbKey, []byte(hexutil.EncodeBig(status)))
+		level.Info(b.logger).Log("msg", "miner", "address", address.Hex(), "status", status)
+		err = b.db.Put(db.MinerKey(address.Hex(), db.DisputeStatusKey), []byte(hexutil.EncodeBig(status)))
 		if err != nil {
 			level.Error(b.logger).Log("msg", "storing staker dispute status", "err", err)
 		}
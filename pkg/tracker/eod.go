@@ -0,0 +1,66 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+)
+
+// Fixing is the time of day at which an end of day value is fixed.
+type Fixing struct {
+	// TimeOfDay is the time after midnight in the Location, e.g. 16*time.Hour for a market close at 4pm.
+	TimeOfDay time.Duration
+	// Location defaults to UTC.
+	Location *time.Location
+	// Weekdays skips the fixings on Saturdays and Sundays for markets closed on weekends.
+	Weekdays bool
+	// Lookback is how long before the fixing the latest value of a source can be.
+	// Sources without a value in the lookback window don't contribute. Zero accepts values of any age.
+	Lookback time.Duration
+}
+
+// Last returns the latest fixing time at or before at.
+func (f Fixing) Last(at time.Time) time.Time {
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	y, m, d := at.In(loc).Date()
+	h := int(f.TimeOfDay / time.Hour)
+	min := int(f.TimeOfDay % time.Hour / time.Minute)
+	sec := int(f.TimeOfDay % time.Minute / time.Second)
+	// Build the time from the date so that it stays at the same time of day across daylight saving changes.
+	fixing := time.Date(y, m, d, h, min, sec, 0, loc)
+	for fixing.After(at) || (f.Weekdays && (fixing.Weekday() == time.Saturday || fixing.Weekday() == time.Sunday)) {
+		d--
+		fixing = time.Date(y, m, d, h, min, sec, 0, loc)
+	}
+	return fixing
+}
+
+// EOD evaluates the processor at the last fixing before at, so the value
+// stays the same for a whole day and historical values can be checked.
+func EOD(f Fixing, processor IndexProcessor) IndexProcessor {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+		fixing := f.Last(at)
+		if f.Lookback == 0 {
+			return processor(apis, fixing)
+		}
+		recent := make([]*IndexTracker, 0, len(apis))
+		for _, api := range apis {
+			b, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, fixing)
+			if b != nil && fixing.Sub(b.Created) <= f.Lookback {
+				recent = append(recent, api)
+			}
+		}
+		if len(recent) == 0 {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
+		// The sources left out still count towards the coverage.
+		val, confidence, dispersion := processor(recent, fixing)
+		return val, confidence * Coverage(len(recent), len(apis)), dispersion
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestFixingLast(t *testing.T) {
+	utc := Fixing{}
+	at := time.Date(2021, 1, 1, 15, 30, 0, 0, time.UTC)
+	testutil.Equals(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), utc.Last(at))
+	// A fixing exactly at the time is the latest one.
+	testutil.Equals(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), utc.Last(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	amplFixing := Fixing{TimeOfDay: 2 * time.Hour}
+	testutil.Equals(t, time.Date(2020, 12, 31, 2, 0, 0, 0, time.UTC), amplFixing.Last(time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC)))
+
+	ny, err := time.LoadLocation("America/New_York")
+	testutil.Ok(t, err)
+	marketClose := Fixing{TimeOfDay: 16 * time.Hour, Location: ny, Weekdays: true}
+	// The close stays at 4pm local time across the daylight saving change on 2021-03-14.
+	testutil.Equals(t, "2021-03-12 21:00:00 +0000 UTC", marketClose.Last(time.Date(2021, 3, 13, 12, 0, 0, 0, time.UTC)).UTC().String())
+	testutil.Equals(t, "2021-03-15 20:00:00 +0000 UTC", marketClose.Last(time.Date(2021, 3, 15, 21, 0, 0, 0, time.UTC)).UTC().String())
+	// Weekends fall back to the Friday close.
+	testutil.Equals(t, "2021-03-12 21:00:00 +0000 UTC", marketClose.Last(time.Date(2021, 3, 15, 12, 0, 0, 0, time.UTC)).UTC().String())
+}
+
+func TestEOD(t *testing.T) {
+	midnight := clck.Now().UTC().Truncate(24 * time.Hour).Add(-48 * time.Hour)
+	api := &IndexTracker{Identifier: t.Name()}
+	apiOracle.SetRequestValue(api.Identifier, midnight.Add(-10*time.Minute), apiOracle.PriceInfo{Price: 100})
+	apiOracle.SetRequestValue(api.Identifier, midnight.Add(10*time.Minute), apiOracle.PriceInfo{Price: 200})
+	late := &IndexTracker{Identifier: t.Name() + "-late"}
+	apiOracle.SetRequestValue(late.Identifier, midnight.Add(-2*time.Minute), apiOracle.PriceInfo{Price: 110})
+
+	// The value is the one at the fixing before the given time, not at the current time.
+	val, conf, _ := EOD(Fixing{}, MedianAt)([]*IndexTracker{api}, midnight.Add(5*time.Hour))
+	testutil.Equals(t, 100.0, val.Price)
+	testutil.Assert(t, conf > 0, "expected a confidence")
+
+	_, conf, _ = EOD(Fixing{}, MedianAt)([]*IndexTracker{api}, midnight.Add(-time.Hour))
+	testutil.Equals(t, 0.0, conf)
+
+	// Sources without a value in the lookback window are left out but still count towards the coverage.
+	val, conf, _ = EOD(Fixing{Lookback: 5 * time.Minute}, MedianAt)([]*IndexTracker{api, late}, midnight.Add(time.Hour))
+	testutil.Equals(t, 110.0, val.Price)
+	testutil.Equals(t, 0.5, conf)
+}
@@ -21,7 +21,7 @@ func createTracker(name string, logger log.Logger, config *config.Config, db db.
 		}
 	case "balance":
 		{
-			return []Tracker{NewBalanceTracker(logger, db, client, account)}, nil
+			return []Tracker{NewBalanceTracker(logger, config, db, client, account)}, nil
 		}
 	case "disputeStatus":
 		{
@@ -29,7 +29,11 @@ func createTracker(name string, logger log.Logger, config *config.Config, db db.
 		}
 	case "gas":
 		{
-			return []Tracker{NewGasTracker(logger, db, client)}, nil
+			fetcher, err := newFetcher(config)
+			if err != nil {
+				return nil, err
+			}
+			return []Tracker{NewGasTracker(logger, db, client, fetcher)}, nil
 		}
 	case "currentVariables":
 		{
@@ -37,7 +41,7 @@ func createTracker(name string, logger log.Logger, config *config.Config, db db.
 		}
 	case "tributeBalance":
 		{
-			return []Tracker{NewTributeTracker(logger, db, contract, account)}, nil
+			return []Tracker{NewTributeTracker(logger, config, db, contract, account)}, nil
 		}
 	case "indexers":
 		{
@@ -0,0 +1,27 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/fetch"
+)
+
+// transport replaces the http transport of the trackers in tests.
+var transport http.RoundTripper
+
+// newFetcher creates the fetcher for the http requests of the trackers.
+func newFetcher(cfg *config.Config) (*fetch.Fetcher, error) {
+	if transport != nil {
+		return fetch.New(cfg.Fetch, transport), nil
+	}
+	t, err := fetch.NewTransport(cfg.Fetch, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the http transport")
+	}
+	return fetch.New(cfg.Fetch, t), nil
+}
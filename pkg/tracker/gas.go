@@ -15,15 +15,17 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/fetch"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 // GasTracker is the struct that maintains the latest gasprices.
 // note the prices are actually stored in the DB.
 type GasTracker struct {
-	db     db.DB
-	client rpc.ETHClient
-	logger log.Logger
+	db      db.DB
+	client  rpc.ETHClient
+	fetcher *fetch.Fetcher
+	logger  log.Logger
 }
 
 // GasPriceModel is what ETHGasStation returns from queries. Not all fields are filled in.
@@ -37,11 +39,12 @@ func (b *GasTracker) String() string {
 	return "GasTracker"
 }
 
-func NewGasTracker(logger log.Logger, db db.DB, client rpc.ETHClient) *GasTracker {
+func NewGasTracker(logger log.Logger, db db.DB, client rpc.ETHClient, fetcher *fetch.Fetcher) *GasTracker {
 	return &GasTracker{
-		db:     db,
-		client: client,
-		logger: log.With(logger, "component", "gas tracker"),
+		db:      db,
+		client:  client,
+		fetcher: fetcher,
+		logger:  log.With(logger, "component", "gas tracker"),
 	}
 
 }
@@ -57,8 +60,9 @@ func (b *GasTracker) Exec(ctx context.Context) error {
 
 	if big.NewInt(1).Cmp(netID) == 0 {
 		url := "https://ethgasstation.info/json/ethgasAPI.json"
-		req := &FetchRequest{queryURL: url, timeout: time.Duration(15 * time.Second)}
-		payload, err := fetchWithRetries(req)
+		fetchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		payload, err := b.fetcher.Do(fetchCtx, &fetch.Request{URL: url})
+		cancel()
 		if err != nil {
 			gasPrice, err = b.client.SuggestGasPrice(ctx)
 			if err != nil {
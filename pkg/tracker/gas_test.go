@@ -16,7 +16,7 @@ import (
 )
 
 func TestETHGasStation(t *testing.T) {
-	config.OpenTestConfig(t)
+	cfg := config.OpenTestConfig(t)
 	logSetup := util.SetupLogger()
 	logger := logSetup("debug")
 	opts := &rpc.MockOptions{ETHBalance: big.NewInt(300000), Nonce: 1, GasPrice: big.NewInt(7000000000),
@@ -24,8 +24,10 @@ func TestETHGasStation(t *testing.T) {
 	client := rpc.NewMockClientWithValues(opts)
 	DB, cleanup := db.OpenTestDB(t)
 	defer t.Cleanup(cleanup)
-	tracker := NewGasTracker(logger, DB, client)
-	err := tracker.Exec(context.Background())
+	fetcher, err := newFetcher(cfg)
+	testutil.Ok(t, err)
+	tracker := NewGasTracker(logger, DB, client, fetcher)
+	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
 	v, err := DB.Get(db.GasKey)
 	testutil.Ok(t, err)
@@ -5,15 +5,19 @@ package tracker
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"sort"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -22,7 +26,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/apiOracle"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
-	"github.com/yalp/jsonpath"
+	"github.com/tellor-io/telliot/pkg/fetch"
 )
 
 var clck clock.Clock
@@ -31,17 +35,31 @@ func init() {
 	clck = clock.New()
 }
 
-var indexes map[string][]*IndexTracker
+var (
+	indexes   map[string][]*IndexTracker
+	indexesMu sync.RWMutex
+)
 
 // GetIndexes returns indexes for outside package usage.
+// The returned map isn't changed, reloading the index file replaces it.
 func GetIndexes() map[string][]*IndexTracker {
+	indexesMu.RLock()
+	defer indexesMu.RUnlock()
 	return indexes
 }
 
+func setIndexes(i map[string][]*IndexTracker) {
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	indexes = i
+}
+
 // parseIndexFile parses indexes.json file and returns a *IndexTracker,
-// for every URL in index file, also a map[string][]string that describes which APIs
+// for every URL in index file, also a map[string][]*IndexTracker that describes which APIs
 // influence which symbols.
-func parseIndexFile(cfg *config.Config, DB db.DB) (trackersPerURL map[string]*IndexTracker, symbolsForAPI map[string][]string, err error) {
+// The running trackers of APIs that didn't change are returned instead of new ones
+// so that they keep their state.
+func parseIndexFile(cfg *config.Config, DB db.DB, running map[string]*IndexTracker) (trackersPerURL map[string]*IndexTracker, symbolIndexes map[string][]*IndexTracker, err error) {
 
 	// Load index file.
 	indexFilePath := filepath.Join(cfg.ConfigFolder, "indexes.json")
@@ -55,95 +73,146 @@ func parseIndexFile(cfg *config.Config, DB db.DB) (trackersPerURL map[string]*In
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "parse index file")
 	}
-	// Keep track of tracker per symbol.
-	indexes = make(map[string][]*IndexTracker)
+	// Expand any env variables with their values from the .env file.
+	// Ignore file doesn't exist errors.
+	if _, err := godotenv.Read(cfg.EnvFile); err != nil && !os.IsNotExist(err) {
+		return nil, nil, errors.Wrap(err, "reading .env file")
+	}
+	// The limits of the hosts are set from the whole file so that
+	// raised and removed limits take effect when the file is reloaded.
+	limits := hostLimits(cfg.SourceRateLimit, baseIndexes)
+	// All the http APIs share the connections of one fetcher.
+	fetcher, err := newFetcher(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 	// Build a tracker for each unique URL.
 	trackersPerURL = make(map[string]*IndexTracker)
 	// Keep track of which APIs influence which symbols so we know what to update later.
-	symbolsForAPI = make(map[string][]string)
+	symbolsForAPI := make(map[string][]string)
 
 	for symbol, apis := range baseIndexes {
 		for _, api := range apis {
+			// Identify the API before the secrets are expanded
+			// so that they don't end up in logs and metric labels.
+			id := api.identifier()
 			// Tracker for this API already added?
-			_, ok := trackersPerURL[api.URL]
+			_, ok := trackersPerURL[id]
 			if !ok {
-				// Expand any env variables with their values from the .env file.
-				_, err := godotenv.Read(cfg.EnvFile)
-				// Ignore file doesn't exist errors.
-				if err != nil && !os.IsNotExist(err) {
-					return nil, nil, errors.Wrap(err, "reading .env file")
+				current, err := newIndexTracker(cfg, DB, fetcher, limits, id, api)
+				if err != nil {
+					return nil, nil, err
 				}
-				api.URL = os.Expand(api.URL, func(key string) string {
-					return os.Getenv(key)
-				})
-
-				var name string
-				var source DataSource
-
-				// Create an index tracker based on the api type.
-				// Default value for the api type.
-				if api.Type == "" {
-					api.Type = httpIndexType
-				}
-				switch api.Type {
-				case httpIndexType:
-					{
-						source = &JSONapi{&FetchRequest{queryURL: api.URL, timeout: cfg.FetchTimeout.Duration}}
-						u, err := url.Parse(api.URL)
-						if err != nil {
-							return nil, nil, errors.Wrapf(err, "invalid API URL: %s", api.URL)
-						}
-						name = u.Host
-					}
-				case fileIndexType:
-					{
-						source = &JSONfile{filepath: filepath.Join(cfg.ConfigFolder, api.URL)}
-						name = filepath.Base(api.URL)
-					}
-				case ethereumIndexType:
-					{
-						// Skip as there is no ethereum index type in the index file right now.
-						continue
-					}
-				default:
-					return nil, nil, errors.New("unknown index type for index object")
+				if current == nil {
+					continue
 				}
-
-				if api.Interval.Duration > 0 && (api.Interval.Duration < cfg.TrackerSleepCycle.Duration) {
-					return nil, nil, errors.New("api interval can't be smaller than the global tracker cycle")
-				}
-
-				// Default value for the parser.
-				if api.Parser == "" {
-					api.Parser = jsonPathIndexParser
-				}
-				current := &IndexTracker{
-					Name:       name,
-					Identifier: api.URL,
-					Source:     source,
-					DB:         DB,
-					Interval:   api.Interval.Duration,
-					Param:      api.Param,
-				}
-
-				trackersPerURL[api.URL] = current
+				trackersPerURL[id] = current
 			}
 			// Now we definitely have one.
-			thisOne := trackersPerURL[api.URL]
+			thisOne := trackersPerURL[id]
 
-			// Insert add it and it's more specific variant to the symbol -> api map.
-			indexes[symbol] = append(indexes[symbol], thisOne)
+			// Save this for later so we can build the symbol -> api map.
+			// Add it and it's more specific variant.
 			specificName := fmt.Sprintf("%s~%s", symbol, thisOne.Name)
-			indexes[specificName] = append(indexes[specificName], thisOne)
+			symbolsForAPI[id] = append(symbolsForAPI[id], symbol, specificName)
+		}
+	}
 
-			// Save this for later so we can build the api->symbol map.
-			symbolsForAPI[api.URL] = append(symbolsForAPI[api.URL], symbol, specificName)
+	// Keep track of tracker per symbol.
+	symbolIndexes = make(map[string][]*IndexTracker)
+	for id, symbols := range symbolsForAPI {
+		sort.Strings(symbols)
+		current := trackersPerURL[id]
+		current.Symbols = symbols
+		if old, ok := running[id]; ok && reflect.DeepEqual(old.definition, current.definition) {
+			if reflect.DeepEqual(old.Symbols, symbols) {
+				current = old
+			} else {
+				// The API feeds other symbols now, keep its health.
+				current.health = old.health
+			}
+		}
+		trackersPerURL[id] = current
+		for _, symbol := range symbols {
+			symbolIndexes[symbol] = append(symbolIndexes[symbol], current)
 		}
 	}
 	return
 
 }
 
+// newIndexTracker creates the tracker of an API from the index file
+// or returns nil for the API types that aren't tracked.
+func newIndexTracker(cfg *config.Config, DB db.DB, fetcher *fetch.Fetcher, limits map[string]hostLimit, id string, api IndexObject) (*IndexTracker, error) {
+	definition := api
+	api.URL = os.Expand(api.URL, os.Getenv)
+
+	var name string
+	var source DataSource
+
+	// Create an index tracker based on the api type.
+	// Default value for the api type.
+	if api.Type == "" {
+		api.Type = httpIndexType
+	}
+	switch api.Type {
+	case httpIndexType:
+		{
+			req, err := api.fetchRequest(id)
+			if err != nil {
+				return nil, err
+			}
+			u, err := url.Parse(api.URL)
+			if err != nil {
+				// Not wrapped as the error contains the expanded URL.
+				return nil, errors.Errorf("invalid API URL: %s", id)
+			}
+			req.Limiter = limiterForHost(u.Host, limits[u.Host])
+			source = &JSONapi{Request: req, fetcher: fetcher, timeout: cfg.FetchTimeout.Duration}
+			name = u.Host
+		}
+	case fileIndexType:
+		{
+			source = &JSONfile{filepath: filepath.Join(cfg.ConfigFolder, api.URL)}
+			name = filepath.Base(api.URL)
+		}
+	case ethereumIndexType:
+		{
+			// Skip as there is no ethereum index type in the index file right now.
+			return nil, nil
+		}
+	default:
+		return nil, errors.New("unknown index type for index object")
+	}
+
+	if api.Interval.Duration > 0 && (api.Interval.Duration < cfg.TrackerSleepCycle.Duration) {
+		return nil, errors.New("api interval can't be smaller than the global tracker cycle")
+	}
+
+	parser, err := newPayloadParser(api)
+	if err != nil {
+		return nil, errors.Wrapf(err, "API: %s", id)
+	}
+	var rec *recorder
+	if cfg.RecordFile != "" {
+		if rec, err = openRecorder(cfg.RecordFile); err != nil {
+			return nil, err
+		}
+	}
+	return &IndexTracker{
+		Name:       name,
+		Identifier: id,
+		Source:     source,
+		DB:         DB,
+		Interval:   api.Interval.Duration,
+		Param:      api.Param,
+		definition: definition,
+		parser:     parser,
+		health:     newHealthMonitor(cfg.SourceHealth, api.Interval.Duration),
+		recorder:   rec,
+	}, nil
+}
+
 // BuildIndexTrackers creates and initializes a new tracker instance.
 func BuildIndexTrackers(cfg *config.Config, db db.DB) ([]Tracker, error) {
 	err := apiOracle.EnsureValueOracle()
@@ -153,19 +222,53 @@ func BuildIndexTrackers(cfg *config.Config, db db.DB) ([]Tracker, error) {
 
 	// Load trackers from the index file,
 	// and build a tracker for each unique URL, symbol
-	indexers, symbolsForAPI, err := parseIndexFile(cfg, db)
+	indexers, symbolIndexes, err := parseIndexFile(cfg, db, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start the PSR system that will feed from these indexes.
+	err = checkPSRs(symbolIndexes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize PSRs")
+	}
+	setIndexes(symbolIndexes)
+
+	return sortedTrackers(indexers), nil
+}
+
+// reloadIndexes parses the index file again and replaces the indexes when the PSRs can use it.
+// It returns the trackers to run instead of the running index trackers, the trackers of
+// the APIs that didn't change are kept so that their history and health aren't lost.
+func reloadIndexes(cfg *config.Config, db db.DB, running []Tracker) ([]Tracker, error) {
+	runningPerURL := make(map[string]*IndexTracker)
+	for _, t := range running {
+		if i, ok := t.(*IndexTracker); ok {
+			runningPerURL[i.Identifier] = i
+		}
+	}
+	indexers, symbolIndexes, err := parseIndexFile(cfg, db, runningPerURL)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkPSRs(symbolIndexes); err != nil {
+		return nil, errors.Wrap(err, "checking the PSRs")
+	}
+	for id, old := range runningPerURL {
+		if current, ok := indexers[id]; !ok || current.String() != old.String() {
+			old.deleteHealthMetrics()
+		}
+	}
+	setIndexes(symbolIndexes)
+	return sortedTrackers(indexers), nil
+}
 
+// sortedTrackers returns the trackers sorted by URL so we return the same order every time.
+func sortedTrackers(indexers map[string]*IndexTracker) []Tracker {
 	var sortedIndexers []string
-	// Set the reverse map.
-	for api, symbols := range symbolsForAPI {
-		indexers[api].Symbols = symbols
+	for api := range indexers {
 		sortedIndexers = append(sortedIndexers, api)
 	}
-
-	// Sort the Indexer array so we return the same order every time.
 	sort.Strings(sortedIndexers)
 
 	// Make an array of trackers to be sent to Runner.
@@ -173,14 +276,7 @@ func BuildIndexTrackers(cfg *config.Config, db db.DB) ([]Tracker, error) {
 	for idx, api := range sortedIndexers {
 		trackers[idx] = indexers[api]
 	}
-
-	// Start the PSR system that will feed from these indexes.
-	err = InitPSRs()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to initialize PSRs")
-	}
-
-	return trackers, nil
+	return trackers
 }
 
 // IndexType -> index type for IndexObject.
@@ -197,6 +293,9 @@ type IndexParser string
 
 const (
 	jsonPathIndexParser IndexParser = "jsonPath"
+	csvIndexParser      IndexParser = "csv"
+	tsvIndexParser      IndexParser = "tsv"
+	regexIndexParser    IndexParser = "regex"
 )
 
 // IndexObject will be used in parsing index file.
@@ -206,6 +305,81 @@ type IndexObject struct {
 	Parser   IndexParser     `json:"format"`
 	Param    string          `json:"param"`
 	Interval config.Duration `json:"interval"`
+	// VolumeParam and TimestampParam select the volume and the time of the value
+	// with the same format as Param.
+	VolumeParam    string `json:"volumeParam"`
+	TimestampParam string `json:"timestampParam"`
+	// Invert uses 1/value for sources quoted backwards.
+	Invert bool `json:"invert"`
+	// Scale multiplies the value, e.g. 0.01 for sources quoted in cents.
+	Scale float64 `json:"scale"`
+	// Method defaults to GET or to POST when there is a body.
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	// Body is sent as it is when it is a JSON string and encoded otherwise,
+	// e.g. {"query": "{ pairs { id } }"} for a GraphQL API.
+	Body json.RawMessage `json:"body"`
+	// RateLimit and RateBurst override the rate limit of the config for the host of the API.
+	// The limit is shared by all the APIs of the host and the lowest one of them is used.
+	RateLimit float64 `json:"rateLimit"`
+	RateBurst int     `json:"rateBurst"`
+}
+
+func (o IndexObject) method() string {
+	if o.Method != "" {
+		return strings.ToUpper(o.Method)
+	}
+	if len(o.Body) > 0 {
+		return http.MethodPost
+	}
+	return http.MethodGet
+}
+
+// identifier identifies the API by its URL before the env variables are expanded.
+// Requests with a different method or body to the same URL are different APIs.
+func (o IndexObject) identifier() string {
+	if len(o.Body) == 0 && o.method() == http.MethodGet {
+		return o.URL
+	}
+	h := sha256.Sum256(o.Body)
+	return fmt.Sprintf("%s %s#%x", o.method(), o.URL, h[:4])
+}
+
+// fetchRequest creates the request of an http API with an already expanded URL
+// and the env variables of its headers and body expanded.
+func (o IndexObject) fetchRequest(name string) (*fetch.Request, error) {
+	req := &fetch.Request{
+		URL:    o.URL,
+		Method: o.method(),
+		Header: make(http.Header),
+		Name:   name,
+	}
+	for k, v := range o.Headers {
+		req.Header.Set(k, expandEnv(v))
+	}
+	if len(o.Body) == 0 {
+		return req, nil
+	}
+	body := string(o.Body)
+	if o.Body[0] == '"' {
+		if err := json.Unmarshal(o.Body, &body); err != nil {
+			return nil, errors.Wrapf(err, "invalid body of API: %s", name)
+		}
+	} else if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Body = []byte(expandEnv(body))
+	return req, nil
+}
+
+var envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces only the ${NAME} env variables
+// so that the $ of GraphQL variables in a body are kept.
+func expandEnv(s string) string {
+	return envVarRegexp.ReplaceAllStringFunc(s, func(v string) string {
+		return os.Getenv(v[2 : len(v)-1])
+	})
 }
 
 type IndexTracker struct {
@@ -217,52 +391,82 @@ type IndexTracker struct {
 	Interval         time.Duration
 	Param            string
 	lastRunTimestamp time.Time
+	// definition is the entry of the API in the index file.
+	definition IndexObject
+	parser     *payloadParser
+	health     *healthMonitor
+	// recorder archives the payloads of the source when recording is enabled.
+	recorder *recorder
 }
 
 type DataSource interface {
-	Get() ([]byte, error)
+	Get(ctx context.Context) ([]byte, error)
 }
 
 type JSONapi struct {
-	Request *FetchRequest
+	Request *fetch.Request
+	fetcher *fetch.Fetcher
+	// timeout bounds all the attempts of a request together.
+	timeout time.Duration
 }
 
-func (j *JSONapi) Get() ([]byte, error) {
-	return fetchWithRetries(j.Request)
+func (j *JSONapi) Get(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+	return j.fetcher.Do(ctx, j.Request)
 }
 
 type JSONfile struct {
 	filepath string
 }
 
-func (j *JSONfile) Get() ([]byte, error) {
+func (j *JSONfile) Get(_ context.Context) ([]byte, error) {
 	return ioutil.ReadFile(j.filepath)
 }
 
 func (i *IndexTracker) Exec(ctx context.Context) error {
 	now := time.Now()
 	if now.Sub(i.lastRunTimestamp) < i.Interval {
+		if i.health != nil {
+			i.updateHealthMetrics(i.Health(clck.Now()))
+		}
 		return nil
 	}
 	i.lastRunTimestamp = now
 
-	payload, err := i.Source.Get()
+	start := clck.Now()
+	payload, err := i.Source.Get(ctx)
+	latency := clck.Now().Sub(start)
+	if i.recorder != nil {
+		i.recorder.record(i.Identifier, start, latency, payload, err)
+	}
 	if err != nil {
+		i.recordHealth(true, latency, clck.Now())
 		return err
 	}
 
-	vals, err := i.ParsePayload(payload)
+	parser := i.parser
+	if parser == nil {
+		parser = &payloadParser{format: jsonPathIndexParser, param: i.Param}
+	}
+	val, ts, err := parser.parse(payload)
 	if err != nil {
+		i.recordHealth(true, latency, clck.Now())
 		return err
 	}
 
-	volume := 0.0
-	if len(vals) >= 2 {
-		volume = vals[1]
+	created := clck.Now()
+	if !ts.IsZero() && ts.Before(created) {
+		// Use the time of the source so that the age of its values is known.
+		created = ts
 	}
-
-	//save the value into our local data window (set 0 volume for now)
-	apiOracle.SetRequestValue(i.Identifier, clck.Now(), apiOracle.PriceInfo{Price: vals[0], Volume: volume})
+	// Sources with their own time repeat the same value until they update it.
+	if latest, _ := apiOracle.GetNearestTwoRequestValue(i.Identifier, clck.Now()); latest == nil || created.After(latest.Created) {
+		//save the value into our local data window
+		apiOracle.SetRequestValue(i.Identifier, created, val)
+	}
+	// Check the value against the other sources before it is used by the PSRs.
+	i.recordHealth(false, latency, clck.Now())
 	//update all the values that depend on these symbols
 	return UpdatePSRs(ctx, i.DB, i.Symbols)
 }
@@ -275,45 +479,21 @@ func (i *IndexTracker) String() string {
 // The input JSON will get queried using JSONPath query language if
 // the JSONPath expression is not empty.
 func (i *IndexTracker) ParsePayload(payload []byte) (vals []float64, err error) {
-
-	var decodedPayload, result interface{}
-	err = json.Unmarshal(payload, &decodedPayload)
+	doc, err := (&payloadParser{format: jsonPathIndexParser}).decode(payload)
 	if err != nil {
-		return
-	}
-
-	// Query the json payload using JSONPath expression if needed.
-	result = decodedPayload
-	if len(strings.TrimSpace(i.Param)) > 0 {
-		if err != nil {
-			return
-		}
-		result, err = jsonpath.Read(decodedPayload, i.Param)
-		if err != nil {
-			return
-		}
+		return nil, err
 	}
-
-	// Expect result to be a slice of float or a single float value.
-	var resultList []interface{}
-	switch result := result.(type) {
-	case []interface{}:
-		resultList = result
-	default:
-		resultList = []interface{}{result}
+	results, err := doc.get(i.Param)
+	if err != nil {
+		return nil, err
 	}
-
-	// Parse each item of slice to a float.
-	vals = make([]float64, 0)
-	for _, a := range resultList {
-		strValue := fmt.Sprintf("%v", a)
-		// Normalize based on american locale.
-		strValue = strings.Replace(strValue, ",", "", -1)
-		val, err := strconv.ParseFloat(strValue, 64)
+	vals = make([]float64, 0, len(results))
+	for _, r := range results {
+		val, err := parseNumber(r)
 		if err != nil {
-			return nil, errors.Wrap(err, "JSON value needs to be a valid float")
+			return nil, err
 		}
 		vals = append(vals, val)
 	}
-	return
+	return vals, nil
 }
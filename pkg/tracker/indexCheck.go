@@ -0,0 +1,116 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// SourceCheck is the result of requesting a source of the index file once.
+type SourceCheck struct {
+	Name       string
+	Identifier string
+	Symbols    []string
+	Value      float64
+	Latency    time.Duration
+	// Deviation is the largest relative deviation of the value
+	// from the median of the values of all sources of one of its symbols.
+	Deviation float64
+	Err       error
+}
+
+// CheckIndexes parses the index file of the config and checks that it has all the symbols required by the PSRs.
+// Unless offline every source is requested once, all of them at the same time.
+// It doesn't change the running indexes.
+func CheckIndexes(ctx context.Context, cfg *config.Config, offline bool) ([]*SourceCheck, error) {
+	indexers, symbolIndexes, err := parseIndexFile(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPSRs(symbolIndexes); err != nil {
+		return nil, err
+	}
+
+	apis := sortedTrackers(indexers)
+	var checks []*SourceCheck
+	for _, t := range apis {
+		api := t.(*IndexTracker)
+		check := &SourceCheck{Name: api.Name, Identifier: api.Identifier}
+		for _, symbol := range api.Symbols {
+			// Skip the source specific variants of the symbols.
+			if !strings.Contains(symbol, "~") {
+				check.Symbols = append(check.Symbols, symbol)
+			}
+		}
+		checks = append(checks, check)
+	}
+	if offline {
+		return checks, nil
+	}
+
+	var wg sync.WaitGroup
+	for i, t := range apis {
+		wg.Add(1)
+		go func(api *IndexTracker, check *SourceCheck) {
+			defer wg.Done()
+			check.Value, check.Latency, check.Err = api.fetch(ctx)
+		}(t.(*IndexTracker), checks[i])
+	}
+	wg.Wait()
+
+	values := make(map[string][]apiOracle.PriceInfo)
+	for _, c := range checks {
+		if c.Err != nil {
+			continue
+		}
+		for _, symbol := range c.Symbols {
+			values[symbol] = append(values[symbol], apiOracle.PriceInfo{Price: c.Value})
+		}
+	}
+	for _, c := range checks {
+		if c.Err != nil {
+			continue
+		}
+		for _, symbol := range c.Symbols {
+			if len(values[symbol]) < 2 {
+				continue
+			}
+			median := Median(append([]apiOracle.PriceInfo(nil), values[symbol]...)).Price
+			if median == 0 {
+				continue
+			}
+			if d := math.Abs(c.Value-median) / math.Abs(median); d > c.Deviation {
+				c.Deviation = d
+			}
+		}
+	}
+	return checks, nil
+}
+
+// fetch requests and parses the source once without storing its value.
+func (i *IndexTracker) fetch(ctx context.Context) (float64, time.Duration, error) {
+	start := time.Now()
+	payload, err := i.Source.Get(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return 0, latency, err
+	}
+	parser := i.parser
+	if parser == nil {
+		parser = &payloadParser{format: jsonPathIndexParser, param: i.Param}
+	}
+	val, _, err := parser.parse(payload)
+	if err != nil {
+		return 0, latency, errors.Wrap(err, "parsing the payload")
+	}
+	return val.Price, latency, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestCheckIndexes(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	defer func(old map[int]ValueGenerator) { PSRs = old }(PSRs)
+	PSRs = map[int]ValueGenerator{
+		1: &SingleSymbol{symbol: "ETH/USD", granularity: 1000, transform: MedianAt},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/a":
+			_, err = w.Write([]byte(`{"price": 100}`))
+		case "/b":
+			_, err = w.Write([]byte(`{"price": 102}`))
+		case "/c":
+			_, err = w.Write([]byte(`{"price": 150}`))
+		case "/renamed":
+			_, err = w.Write([]byte(`{"last": 100}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "indexes")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+	defer func(folder string) { cfg.ConfigFolder = folder }(cfg.ConfigFolder)
+	cfg.ConfigFolder = dir
+	writeIndexes := func(entries map[string][]map[string]string) {
+		data, err := json.Marshal(entries)
+		testutil.Ok(t, err)
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "indexes.json"), data, 0644))
+	}
+
+	writeIndexes(map[string][]map[string]string{
+		"ETH/USD": {
+			{"URL": srv.URL + "/a", "param": "$.price"},
+			{"URL": srv.URL + "/b", "param": "$.price"},
+			{"URL": srv.URL + "/c", "param": "$.price"},
+			{"URL": srv.URL + "/renamed", "param": "$.price"},
+		},
+	})
+
+	checks, err := CheckIndexes(context.Background(), cfg, true)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, len(checks))
+	for _, c := range checks {
+		testutil.Equals(t, []string{"ETH/USD"}, c.Symbols)
+		testutil.Ok(t, c.Err)
+		testutil.Equals(t, 0.0, c.Value)
+	}
+
+	checks, err = CheckIndexes(context.Background(), cfg, false)
+	testutil.Ok(t, err)
+	results := make(map[string]*SourceCheck)
+	for _, c := range checks {
+		results[c.Identifier] = c
+	}
+	testutil.Equals(t, 100.0, results[srv.URL+"/a"].Value)
+	testutil.Ok(t, results[srv.URL+"/a"].Err)
+	// The median of the sources that responded is 102.
+	testutil.Equals(t, 0.0, results[srv.URL+"/b"].Deviation)
+	testutil.Equals(t, 2/102.0, results[srv.URL+"/a"].Deviation)
+	testutil.Assert(t, results[srv.URL+"/c"].Deviation > 0.4, "unexpected deviation:%v", results[srv.URL+"/c"].Deviation)
+	testutil.NotOk(t, results[srv.URL+"/renamed"].Err, "expected a parsing error")
+
+	// Symbols required by the PSRs must be in the index file.
+	writeIndexes(map[string][]map[string]string{
+		"BTC/USD": {{"URL": srv.URL + "/a", "param": "$.price"}},
+	})
+	_, err = CheckIndexes(context.Background(), cfg, true)
+	testutil.NotOk(t, err, "expected an error for a missing symbol")
+}
@@ -4,12 +4,19 @@
 package tracker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/testutil"
 )
 
@@ -17,7 +24,7 @@ type TestDataSource struct {
 	Payload string
 }
 
-func (i TestDataSource) Get() ([]byte, error) {
+func (i TestDataSource) Get(_ context.Context) ([]byte, error) {
 	return []byte(i.Payload), nil
 }
 
@@ -48,3 +55,116 @@ func TestIndexTracker(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexObjectRequest(t *testing.T) {
+	testutil.Ok(t, os.Setenv("TEST_INDEX_KEY", "secret"))
+	defer os.Unsetenv("TEST_INDEX_KEY")
+
+	var objects []IndexObject
+	testutil.Ok(t, json.Unmarshal([]byte(`[
+		{"URL": "https://api.example.com/price?key=${TEST_INDEX_KEY}"},
+		{
+			"URL": "https://api.example.com/subgraph",
+			"headers": {"x-api-key": "${TEST_INDEX_KEY}"},
+			"body": {"query": "query($id: ID!) { pair(id: $id) { price } }", "variables": {"id": "${TEST_INDEX_KEY}"}}
+		},
+		{"URL": "https://api.example.com/subgraph", "body": {"query": "{ pairs { price } }"}},
+		{"URL": "https://api.example.com/rpc", "method": "put", "body": "price=${TEST_INDEX_KEY}"}
+	]`), &objects))
+
+	ids := make(map[string]bool)
+	for _, o := range objects {
+		id := o.identifier()
+		testutil.Assert(t, !strings.Contains(id, "secret"), "expected no secrets in the identifier:%v", id)
+		ids[id] = true
+	}
+	testutil.Equals(t, len(objects), len(ids))
+
+	req, err := objects[0].fetchRequest(objects[0].identifier())
+	testutil.Ok(t, err)
+	testutil.Equals(t, http.MethodGet, req.Method)
+	testutil.Equals(t, 0, len(req.Body))
+
+	req, err = objects[1].fetchRequest(objects[1].identifier())
+	testutil.Ok(t, err)
+	testutil.Equals(t, http.MethodPost, req.Method)
+	testutil.Equals(t, "secret", req.Header.Get("X-Api-Key"))
+	testutil.Equals(t, "application/json", req.Header.Get("Content-Type"))
+	// Only ${NAME} variables are expanded so the GraphQL variables are kept.
+	testutil.Equals(t, `{"query": "query($id: ID!) { pair(id: $id) { price } }", "variables": {"id": "secret"}}`, string(req.Body))
+
+	req, err = objects[3].fetchRequest(objects[3].identifier())
+	testutil.Ok(t, err)
+	testutil.Equals(t, http.MethodPut, req.Method)
+	testutil.Equals(t, "price=secret", string(req.Body))
+	testutil.Equals(t, "", req.Header.Get("Content-Type"))
+}
+
+func TestReloadIndexes(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	defer func(old map[string][]*IndexTracker) { setIndexes(old) }(GetIndexes())
+
+	data, err := ioutil.ReadFile(filepath.Join(cfg.ConfigFolder, "indexes.json"))
+	testutil.Ok(t, err)
+	var entries map[string][]map[string]interface{}
+	testutil.Ok(t, json.Unmarshal(data, &entries))
+	dir, err := ioutil.TempDir("", "indexes")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+	defer func(folder string) { cfg.ConfigFolder = folder }(cfg.ConfigFolder)
+	cfg.ConfigFolder = dir
+	writeIndexes := func() {
+		data, err := json.Marshal(entries)
+		testutil.Ok(t, err)
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "indexes.json"), data, 0644))
+	}
+
+	writeIndexes()
+	running, err := BuildIndexTrackers(cfg, DB)
+	testutil.Ok(t, err)
+	trackers := make(map[string]*IndexTracker)
+	for _, r := range running {
+		trackers[r.(*IndexTracker).Identifier] = r.(*IndexTracker)
+	}
+
+	// Replace a dead source of ETH/USD.
+	removed := entries["ETH/USD"][0]["URL"].(string)
+	added := "https://api.example.com/eth"
+	entries["ETH/USD"][0] = map[string]interface{}{"URL": added, "param": "$.price"}
+	writeIndexes()
+	reloaded, err := reloadIndexes(cfg, DB, running)
+	testutil.Ok(t, err)
+	testutil.Equals(t, len(running), len(reloaded))
+
+	var sawAdded bool
+	for _, r := range reloaded {
+		api := r.(*IndexTracker)
+		testutil.Assert(t, api.Identifier != removed, "expected the replaced source to be removed")
+		if api.Identifier == added {
+			sawAdded = true
+			continue
+		}
+		// The unchanged sources keep running with their state.
+		testutil.Assert(t, trackers[api.Identifier] == api, "expected the tracker of %v to be kept", api.Identifier)
+	}
+	testutil.Assert(t, sawAdded, "expected the new source to be added")
+	var ethSources []string
+	for _, api := range GetIndexes()["ETH/USD"] {
+		ethSources = append(ethSources, api.Identifier)
+	}
+	testutil.Assert(t, strings.Contains(strings.Join(ethSources, " "), added), "expected the new source in the index:%v", ethSources)
+
+	// Invalid index files don't change the running indexes.
+	before := GetIndexes()
+	delete(entries, "ETH/USD")
+	writeIndexes()
+	_, err = reloadIndexes(cfg, DB, reloaded)
+	testutil.NotOk(t, err, "expected an error for a missing symbol")
+	testutil.Assert(t, reflect.ValueOf(before).Pointer() == reflect.ValueOf(GetIndexes()).Pointer(), "expected the indexes to be kept")
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "indexes.json"), []byte("{"), 0644))
+	_, err = reloadIndexes(cfg, DB, reloaded)
+	testutil.NotOk(t, err, "expected an error for an invalid index file")
+}
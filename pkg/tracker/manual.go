@@ -0,0 +1,32 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+)
+
+// Manual is the PSR of the request IDs whose values are entered with `telliot manual set`.
+// These values are stored separately so it requires no symbols and never produces a value.
+type Manual struct {
+	granularity int64
+}
+
+func (m *Manual) Require(at time.Time) map[string]IndexProcessor {
+	return nil
+}
+
+func (m *Manual) ValueAt(vals map[string]apiOracle.PriceInfo, at time.Time) float64 {
+	return 0
+}
+
+func (m *Manual) Granularity() int64 {
+	return m.granularity
+}
+
+func (m *Manual) Class() string {
+	return ClassManual
+}
@@ -0,0 +1,299 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/yalp/jsonpath"
+)
+
+// payloadParser extracts the value and optionally its volume and time from an API response.
+type payloadParser struct {
+	format         IndexParser
+	param          string
+	volumeParam    string
+	timestampParam string
+	// invert and scale transform the value of sources
+	// that are quoted backwards or in another unit, e.g. 0.01 for cents.
+	invert bool
+	scale  float64
+	// regexps are the compiled params of the regex parser.
+	regexps map[string]*regexp.Regexp
+}
+
+func newPayloadParser(o IndexObject) (*payloadParser, error) {
+	p := &payloadParser{
+		format:         o.Parser,
+		param:          o.Param,
+		volumeParam:    o.VolumeParam,
+		timestampParam: o.TimestampParam,
+		invert:         o.Invert,
+		scale:          o.Scale,
+	}
+	if p.format == "" {
+		p.format = jsonPathIndexParser
+	}
+	switch p.format {
+	case jsonPathIndexParser, csvIndexParser, tsvIndexParser:
+	case regexIndexParser:
+		// Check the expressions early so that a typo doesn't only show up on every request.
+		p.regexps = make(map[string]*regexp.Regexp)
+		for _, param := range []string{p.param, p.volumeParam, p.timestampParam} {
+			if param == "" {
+				continue
+			}
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regex:%v", param)
+			}
+			if re.NumSubexp() != 1 {
+				return nil, errors.Errorf("regex needs exactly one capture group:%v", param)
+			}
+			p.regexps[param] = re
+		}
+		if p.param == "" {
+			return nil, errors.New("regex parser without a param")
+		}
+	default:
+		return nil, errors.Errorf("unknown index format:%v", p.format)
+	}
+	if p.scale < 0 {
+		return nil, errors.Errorf("negative scale:%v", p.scale)
+	}
+	return p, nil
+}
+
+// parse returns the value and the time the source reports for it,
+// the time is zero for sources without a timestampParam.
+func (p *payloadParser) parse(payload []byte) (apiOracle.PriceInfo, time.Time, error) {
+	var info apiOracle.PriceInfo
+	var ts time.Time
+	doc, err := p.decode(payload)
+	if err != nil {
+		return info, ts, err
+	}
+	vals, err := doc.get(p.param)
+	if err != nil {
+		return info, ts, err
+	}
+	if len(vals) == 0 {
+		return info, ts, errors.New("no value in the payload")
+	}
+	if info.Price, err = parseNumber(vals[0]); err != nil {
+		return info, ts, err
+	}
+	// Without a volumeParam the second value of a JSONPath result is the volume.
+	if p.volumeParam == "" && p.format == jsonPathIndexParser && len(vals) >= 2 {
+		if info.Volume, err = parseNumber(vals[1]); err != nil {
+			return info, ts, err
+		}
+	}
+	if p.volumeParam != "" {
+		vols, err := doc.get(p.volumeParam)
+		if err != nil {
+			return info, ts, errors.Wrap(err, "volume")
+		}
+		if len(vols) == 0 {
+			return info, ts, errors.New("no volume in the payload")
+		}
+		if info.Volume, err = parseNumber(vols[0]); err != nil {
+			return info, ts, errors.Wrap(err, "volume")
+		}
+	}
+	if p.timestampParam != "" {
+		stamps, err := doc.get(p.timestampParam)
+		if err != nil {
+			return info, ts, errors.Wrap(err, "timestamp")
+		}
+		if len(stamps) == 0 {
+			return info, ts, errors.New("no timestamp in the payload")
+		}
+		if ts, err = parseTimestamp(stamps[0]); err != nil {
+			return info, ts, err
+		}
+	}
+	if p.invert {
+		if info.Price == 0 {
+			return info, ts, errors.New("can't invert a zero value")
+		}
+		info.Price = 1 / info.Price
+	}
+	if p.scale != 0 {
+		info.Price *= p.scale
+	}
+	return info, ts, nil
+}
+
+func (p *payloadParser) decode(payload []byte) (document, error) {
+	switch p.format {
+	case csvIndexParser, tsvIndexParser:
+		r := csv.NewReader(bytes.NewReader(payload))
+		if p.format == tsvIndexParser {
+			r.Comma = '\t'
+		}
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding the CSV payload")
+		}
+		return csvDocument(rows), nil
+	case regexIndexParser:
+		return textDocument{payload: payload, regexps: p.regexps}, nil
+	default:
+		var decoded interface{}
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			return nil, errors.Wrap(err, "decoding the JSON payload")
+		}
+		return jsonDocument{decoded}, nil
+	}
+}
+
+// document returns the values selected by a param from a decoded payload.
+type document interface {
+	get(param string) ([]string, error)
+}
+
+type jsonDocument struct {
+	decoded interface{}
+}
+
+// get queries the payload using a JSONPath expression,
+// an empty expression selects the whole payload.
+func (d jsonDocument) get(param string) ([]string, error) {
+	result := d.decoded
+	if len(strings.TrimSpace(param)) > 0 {
+		var err error
+		result, err = jsonpath.Read(d.decoded, param)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Expect result to be a slice of values or a single value.
+	var resultList []interface{}
+	switch result := result.(type) {
+	case []interface{}:
+		resultList = result
+	default:
+		resultList = []interface{}{result}
+	}
+	vals := make([]string, 0, len(resultList))
+	for _, a := range resultList {
+		switch a := a.(type) {
+		case float64:
+			// Avoid the exponent format of large numbers like timestamps in milliseconds.
+			vals = append(vals, strconv.FormatFloat(a, 'f', -1, 64))
+		default:
+			vals = append(vals, fmt.Sprintf("%v", a))
+		}
+	}
+	return vals, nil
+}
+
+type csvDocument [][]string
+
+var csvParamRegexp = regexp.MustCompile(`^(.*?)(?:\[(-?\d+)\])?$`)
+
+// get returns a single cell selected by a column and an optional row like Close[-1].
+// The column is a name from the header row or a zero based index for files without a header.
+// The row is zero based and counts from the end when negative - default 0, the first row after the header.
+func (d csvDocument) get(param string) ([]string, error) {
+	m := csvParamRegexp.FindStringSubmatch(strings.TrimSpace(param))
+	column, row := m[1], 0
+	if m[2] != "" {
+		row, _ = strconv.Atoi(m[2])
+	}
+	rows := [][]string(d)
+	col, err := strconv.Atoi(column)
+	if err != nil {
+		if len(rows) == 0 {
+			return nil, errors.New("CSV payload without a header")
+		}
+		col = -1
+		for i, name := range rows[0] {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				col = i
+				break
+			}
+		}
+		if col < 0 {
+			return nil, errors.Errorf("no CSV column:%v", column)
+		}
+		rows = rows[1:]
+	}
+	if row < 0 {
+		row += len(rows)
+	}
+	if row < 0 || row >= len(rows) {
+		return nil, errors.Errorf("no CSV row %v in %v rows", m[2], len(rows))
+	}
+	if col < 0 || col >= len(rows[row]) {
+		return nil, errors.Errorf("no CSV column %v in row %v", column, row)
+	}
+	return []string{rows[row][col]}, nil
+}
+
+type textDocument struct {
+	payload []byte
+	regexps map[string]*regexp.Regexp
+}
+
+// get returns the capture group of the first match of a regular expression
+// compiled by newPayloadParser.
+func (d textDocument) get(param string) ([]string, error) {
+	re, ok := d.regexps[param]
+	if !ok {
+		return nil, errors.Errorf("regex not compiled:%v", param)
+	}
+	m := re.FindSubmatch(d.payload)
+	if len(m) < 2 {
+		return nil, errors.Errorf("no match for regex:%v", param)
+	}
+	return []string{string(m[1])}, nil
+}
+
+func parseNumber(v string) (float64, error) {
+	// Normalize based on american locale.
+	v = strings.Replace(strings.TrimSpace(v), ",", "", -1)
+	val, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "value needs to be a valid float")
+	}
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, errors.Errorf("invalid value:%v", v)
+	}
+	return val, nil
+}
+
+// parseTimestamp accepts unix timestamps in seconds or milliseconds and RFC3339 times.
+func parseTimestamp(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	secs, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid timestamp:%v", v)
+	}
+	// Timestamps in seconds will stay below this until the year 33658.
+	if secs > 1e12 {
+		secs /= 1000
+	}
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(frac*1e9)), nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestPayloadParser(t *testing.T) {
+	csvPayload := "Date,Open,Close,Volume\n2021-01-29,100,101.5,2000\n2021-01-28,99,\"1,000.25\",1500\n"
+	for _, tc := range []struct {
+		name     string
+		object   IndexObject
+		payload  string
+		expected apiOracle.PriceInfo
+		ts       time.Time
+	}{
+		{
+			name:     "jsonPath with volume",
+			object:   IndexObject{Param: "$[0][4,5]"},
+			payload:  `[[1610582400000,"0.9998","1.005","0.9993","1.0011","122598118.7"]]`,
+			expected: apiOracle.PriceInfo{Price: 1.0011, Volume: 122598118.7},
+		},
+		{
+			name:     "jsonPath with volume and timestamp params",
+			object:   IndexObject{Param: "$.price", VolumeParam: "$.volume", TimestampParam: "$.time"},
+			payload:  `{"price": "1,234.5", "volume": 10, "time": 1611964800000}`,
+			expected: apiOracle.PriceInfo{Price: 1234.5, Volume: 10},
+			ts:       time.Unix(1611964800, 0),
+		},
+		{
+			name:     "csv column names",
+			object:   IndexObject{Parser: csvIndexParser, Param: "close", VolumeParam: "Volume", TimestampParam: "Date"},
+			payload:  csvPayload,
+			expected: apiOracle.PriceInfo{Price: 101.5, Volume: 2000},
+			ts:       time.Date(2021, 1, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "csv last row",
+			object:   IndexObject{Parser: csvIndexParser, Param: "Close[-1]"},
+			payload:  csvPayload,
+			expected: apiOracle.PriceInfo{Price: 1000.25},
+		},
+		{
+			name:     "tsv column index",
+			object:   IndexObject{Parser: tsvIndexParser, Param: "1[1]"},
+			payload:  "a\t1\nb\t2\n",
+			expected: apiOracle.PriceInfo{Price: 2},
+		},
+		{
+			name:     "regex",
+			object:   IndexObject{Parser: regexIndexParser, Param: `<span id="last">([\d.,]+)</span>`, TimestampParam: `data-time="([^"]+)"`},
+			payload:  `<div data-time="2021-01-29T16:00:00Z"><span id="last">21.5</span></div>`,
+			expected: apiOracle.PriceInfo{Price: 21.5},
+			ts:       time.Date(2021, 1, 29, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "invert",
+			object:   IndexObject{Param: "$.rate", Invert: true},
+			payload:  `{"rate": 0.0005}`,
+			expected: apiOracle.PriceInfo{Price: 2000},
+		},
+		{
+			name:     "scale",
+			object:   IndexObject{Param: "$.cents", Scale: 0.01},
+			payload:  `{"cents": 12345}`,
+			expected: apiOracle.PriceInfo{Price: 123.45},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := newPayloadParser(tc.object)
+			testutil.Ok(t, err)
+			val, ts, err := p.parse([]byte(tc.payload))
+			testutil.Ok(t, err)
+			testutil.Equals(t, tc.expected, val)
+			testutil.Assert(t, ts.Equal(tc.ts), "unexpected timestamp:%v", ts)
+		})
+	}
+
+	for _, o := range []IndexObject{
+		{Parser: "xml", Param: "$.price"},
+		{Parser: regexIndexParser, Param: `price: \d+`},
+		{Parser: regexIndexParser, Param: `(`},
+		{Param: "$.price", Scale: -1},
+	} {
+		_, err := newPayloadParser(o)
+		testutil.NotOk(t, err, "expected an invalid index object:%+v", o)
+	}
+
+	p, err := newPayloadParser(IndexObject{Parser: csvIndexParser, Param: "High"})
+	testutil.Ok(t, err)
+	_, _, err = p.parse([]byte(csvPayload))
+	testutil.NotOk(t, err, "expected a missing column error")
+	p, err = newPayloadParser(IndexObject{Param: "$.rate", Invert: true})
+	testutil.Ok(t, err)
+	_, _, err = p.parse([]byte(`{"rate": 0}`))
+	testutil.NotOk(t, err, "expected an error for inverting zero")
+}
+
+func TestExecSourceTimestamp(t *testing.T) {
+	config.OpenTestConfig(t)
+	updated := clck.Now().Add(-time.Hour).Truncate(time.Second)
+	source := &flakySource{payload: `{"price": 10, "time": "` + updated.Format(time.RFC3339) + `"}`}
+	p, err := newPayloadParser(IndexObject{Param: "$.price", TimestampParam: "$.time"})
+	testutil.Ok(t, err)
+	api := &IndexTracker{
+		Name:       "source",
+		Identifier: t.Name(),
+		Source:     source,
+		parser:     p,
+	}
+
+	// The value is stored with the time of the source and repeats of it are ignored.
+	testutil.Ok(t, api.Exec(context.Background()))
+	testutil.Ok(t, api.Exec(context.Background()))
+	values := apiOracle.GetRequestValuesForTime(api.Identifier, clck.Now(), 2*time.Hour)
+	testutil.Equals(t, 1, len(values))
+	testutil.Assert(t, values[0].Created.Equal(updated), "unexpected time:%v", values[0].Created)
+}
@@ -4,13 +4,11 @@
 package tracker
 
 import (
-	"fmt"
 	"math"
 	"sort"
 	"time"
 
 	"github.com/tellor-io/telliot/pkg/apiOracle"
-	"github.com/tellor-io/telliot/pkg/config"
 )
 
 const RequestID_TRB_ETH int = 43
@@ -19,12 +17,12 @@ var PSRs = map[int]ValueGenerator{
 	1: &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: MedianAt},
 	2: &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: MedianAt},
 	3: &SingleSymbol{symbol: "BNB/USD", granularity: 1000000, transform: MedianAt},
-	4: &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, ExpDecay)},
+	4: &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, ExpDecay), class: ClassAverage},
 	5: &SingleSymbol{symbol: "ETH/BTC", granularity: 1000000, transform: MedianAt},
 	6: &SingleSymbol{symbol: "BNB/BTC", granularity: 1000000, transform: MedianAt},
 	7: &SingleSymbol{symbol: "BNB/ETH", granularity: 1000000, transform: MedianAt},
-	8: &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, ExpDecay)},
-	9: &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: MedianAtEOD},
+	8: &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, ExpDecay), class: ClassAverage},
+	9: &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: MedianAtEOD, class: ClassEOD},
 	// For more details see https://docs.google.com/document/d/1RFCApk1PznMhSRVhiyFl_vBDPA4mP2n1dTmfqjvuTNw/edit
 	10: &Ampl{granularity: 1000000},
 	11: &SingleSymbol{symbol: "ZEC/ETH", granularity: 1000000, transform: MedianAt},
@@ -40,7 +38,7 @@ var PSRs = map[int]ValueGenerator{
 	21: &SingleSymbol{symbol: "IOTA/USD", granularity: 1000000, transform: MedianAt},
 	22: &SingleSymbol{symbol: "ETC/USD", granularity: 1000000, transform: MedianAt},
 	23: &SingleSymbol{symbol: "ETH/PAX", granularity: 1000000, transform: MedianAt},
-	24: &SingleSymbol{symbol: "ETH/BTC", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay)},
+	24: &SingleSymbol{symbol: "ETH/BTC", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay), class: ClassAverage},
 	25: &SingleSymbol{symbol: "USDC/USDT", granularity: 1000000, transform: MedianAt},
 	26: &SingleSymbol{symbol: "XTZ/USD", granularity: 1000000, transform: MedianAt},
 	27: &SingleSymbol{symbol: "LINK/USD", granularity: 1000000, transform: MedianAt},
@@ -58,15 +56,15 @@ var PSRs = map[int]ValueGenerator{
 	39: &SingleSymbol{symbol: "DAI/USD", granularity: 1000000, transform: MedianAt},
 	40: &SingleSymbol{symbol: "STEEM/BTC", granularity: 1000000, transform: MedianAt},
 	// It is three month average for US PCE (monthly levels): https://www.bea.gov/data/personal-consumption-expenditures-price-index-excluding-food-and-energy
-	41:                &SingleSymbol{symbol: "USPCE", granularity: 1000, transform: ManualEntry},
-	42:                &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: MedianAtEOD},
+	41:                &Manual{granularity: 1000},
+	42:                &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: MedianAtEOD, class: ClassEOD},
 	RequestID_TRB_ETH: &SingleSymbol{symbol: "TRB/ETH", granularity: 1000000, transform: MedianAt},
-	44:                &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay)},
-	45:                &SingleSymbol{symbol: "TRB/USD", granularity: 1000000, transform: MedianAtEOD},
-	46:                &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay)},
+	44:                &SingleSymbol{symbol: "BTC/USD", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay), class: ClassAverage},
+	45:                &SingleSymbol{symbol: "TRB/USD", granularity: 1000000, transform: MedianAtEOD, class: ClassEOD},
+	46:                &SingleSymbol{symbol: "ETH/USD", granularity: 1000000, transform: TimeWeightedAvg(1*time.Hour, NoDecay), class: ClassAverage},
 	47:                &SingleSymbol{symbol: "BSV/USD", granularity: 1000000, transform: MedianAt},
 	48:                &SingleSymbol{symbol: "MAKER/USD", granularity: 1000000, transform: MedianAt},
-	49:                &SingleSymbol{symbol: "BCH/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, NoDecay)},
+	49:                &SingleSymbol{symbol: "BCH/USD", granularity: 1000000, transform: TimeWeightedAvg(24*time.Hour, NoDecay), class: ClassAverage},
 	50:                &SingleSymbol{symbol: "TRB/USD", granularity: 1000000, transform: MedianAt},
 	51:                &SingleSymbol{symbol: "XMR/USD", granularity: 1000000, transform: MedianAt},
 	52:                &SingleSymbol{symbol: "XFT/USD", granularity: 1000000, transform: MedianAt},
@@ -74,7 +72,8 @@ var PSRs = map[int]ValueGenerator{
 	54:                &SingleSymbol{symbol: "WAVES/USD", granularity: 1000000, transform: MedianAt},
 	55:                &SingleSymbol{symbol: "OGN/USD", granularity: 1000000, transform: MedianAt},
 	56:                &SingleSymbol{symbol: "VIXEOD", granularity: 1000000, transform: MedianAt},
-	57:                &SingleSymbol{symbol: "DEFITVL", granularity: 1000000, transform: MeanAt},
+	// A single broken source can't move the mean of the filtered values.
+	57: &SingleSymbol{symbol: "DEFITVL", granularity: 1000000, transform: MADFilteredMeanAt(3)},
 }
 
 // ExpDecay maps values of x between 0 (brand new) and 1 (old) to weights between 0 and 1
@@ -94,35 +93,51 @@ func NoDecay(x float64) (float64, float64) {
 	return 1, 1
 }
 
+// TimeWeightedAvg returns the average of the values recorded over the interval weighted by their age.
+// Every source counts towards the coverage with the fraction of the interval its values span,
+// the freshness is that of the latest values and the agreement is that of the averages of each source.
 func TimeWeightedAvg(interval time.Duration, weightFn func(float64) (float64, float64)) IndexProcessor {
-	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
-		cfg := config.GetConfig()
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+		configured := len(apis)
 		sum := 0.0
 		weightSum := 0.0
-		numVals := 0
-		minTime := at
 		maxVolume := 0.0
-		for _, api := range apis {
+		span := 0.0
+		freshness := 0.0
+		var averages []apiOracle.PriceInfo
+		for _, api := range healthy(apis, at) {
 			values := apiOracle.GetRequestValuesForTime(api.Identifier, at, interval)
+			apiSum := 0.0
+			apiWeight := 0.0
+			oldest := at
+			var newest time.Time
 			for _, v := range values {
 				normDelta := at.Sub(v.Created).Seconds() / interval.Seconds()
 				weight, _ := weightFn(normDelta)
-				sum += v.Price * weight
-				weightSum += weight
-				numVals += 1
-				if minTime.Sub(v.Created).Seconds() > 0 {
-					minTime = v.Created
+				apiSum += v.Price * weight
+				apiWeight += weight
+				if v.Created.Before(oldest) {
+					oldest = v.Created
+				}
+				if v.Created.After(newest) {
+					newest = v.Created
 				}
 				if v.Volume > maxVolume {
 					maxVolume = v.Volume
 				}
 			}
+			if apiWeight == 0 {
+				continue
+			}
+			sum += apiSum
+			weightSum += apiWeight
+			averages = append(averages, apiOracle.PriceInfo{Price: apiSum / apiWeight})
+			span += math.Min(at.Sub(oldest).Seconds()/interval.Seconds(), 1)
+			freshness += Freshness(at.Sub(newest))
+		}
+		if len(averages) == 0 {
+			return apiOracle.PriceInfo{}, 0, 0
 		}
-		// Number of APIs * rate * interval.
-		maxWeight := float64(len(apis)) * (1 / cfg.TrackerSleepCycle.Duration.Seconds()) * interval.Seconds()
-		// Average weight is the integral of the weight fn over [0,1].
-		_, avgWeight := weightFn(0)
-		targetWeight := maxWeight * avgWeight
 
 		var result apiOracle.PriceInfo
 		result.Price = sum / weightSum
@@ -131,65 +146,64 @@ func TimeWeightedAvg(interval time.Duration, weightFn func(float64) (float64, fl
 		// Works well when the time averaging window is equal to the interval of volume reporting
 		// ie, 24 hour average on an api that returns 24hr volume
 		result.Volume = maxVolume
-		// if math.Min(weightSum/targetWeight, 1.0) < .5{
-		// 	values,_ := apiOracle.GetNearestTwoRequestValue(apis[0].Identifier, at)
-		// 	fmt.Println("not enough data for time series, series starts : ", values.Created)
-		// }
-		//fmt.Println("Time Weighted: ", result)
-		return result, math.Min(weightSum/targetWeight, 1.0)
+		coverage := span / float64(configured)
+		return result, Confidence(freshness/float64(len(averages)), coverage, Agreement(averages)), dispersion(averages)
 	}
 }
 
+// VolumeWeightedAPIs returns the volume weighted average of the values the processor returns for each API.
+// The average confidence of these values takes the place of the freshness.
 func VolumeWeightedAPIs(processor IndexProcessor) IndexProcessor {
-	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
 		var results []apiOracle.PriceInfo
 		totalConfidence := 0.0
 		for _, api := range apis {
-			value, confidence := processor([]*IndexTracker{api}, at)
+			// The processor only gets a single API so there is no dispersion.
+			value, confidence, _ := processor([]*IndexTracker{api}, at)
 			if confidence > 0 {
 				results = append(results, value)
 				totalConfidence += confidence
 			}
 		}
-		return VolumeWeightedAvg(results), totalConfidence / float64(len(results))
+		if len(results) == 0 {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
+		return VolumeWeightedAvg(results), Confidence(totalConfidence/float64(len(results)), Coverage(len(results), len(apis)), Agreement(results)), dispersion(results)
 	}
 }
 
+// getLatest returns the latest value of every healthy API and their confidence.
 func getLatest(apis []*IndexTracker, at time.Time) ([]apiOracle.PriceInfo, float64) {
+	values, freshness := latestValues(apis, at)
+	if len(values) == 0 {
+		return nil, 0
+	}
+	return values, Confidence(freshness, Coverage(len(values), len(apis)), Agreement(values))
+}
+
+// latestValues returns the latest value of every healthy API and their average freshness.
+func latestValues(apis []*IndexTracker, at time.Time) ([]apiOracle.PriceInfo, float64) {
 	var values []apiOracle.PriceInfo
-	totalConf := 0.0
-	for _, api := range apis {
+	freshness := 0.0
+	for _, api := range healthy(apis, at) {
 		b, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, at)
 		if b != nil {
-			// Penalize values more than 5 minutes old.
-			totalConf += math.Min(5/at.Sub(b.Created).Minutes(), 1.0)
+			freshness += Freshness(at.Sub(b.Created))
 			values = append(values, b.PriceInfo)
 		}
 	}
-	return values, totalConf / float64(len(apis))
-}
-
-func MedianAt(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
-	values, confidence := getLatest(apis, at)
-	if confidence == 0 {
-		return apiOracle.PriceInfo{}, 0
+	if len(values) == 0 {
+		return nil, 0
 	}
-	return Median(values), confidence
+	return values, freshness / float64(len(values))
 }
 
-func ManualEntry(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
-	vals, confidence := getLatest(apis, at)
+func MedianAt(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+	values, confidence := getLatest(apis, at)
 	if confidence == 0 {
-		return apiOracle.PriceInfo{}, 0
-	}
-	for _, val := range vals {
-		if int64(val.Volume) < clck.Now().Unix() {
-			fmt.Println("Pulled Timestamp: ", val.Volume)
-			fmt.Println("Warning: Manual Data Entry is expired, please update")
-			return apiOracle.PriceInfo{}, 0
-		}
+		return apiOracle.PriceInfo{}, 0, 0
 	}
-	return Median(vals), confidence
+	return Median(values), confidence, dispersion(values)
 }
 
 func MaxPSRID() uint64 {
@@ -202,11 +216,9 @@ func MaxPSRID() uint64 {
 	return uint64(maxID)
 }
 
-func MedianAtEOD(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
-	now := clck.Now().UTC()
-	d := 24 * time.Hour
-	eod := now.Truncate(d)
-	return MedianAt(apis, eod)
+// MedianAtEOD returns the median of the latest values at the last midnight UTC before at.
+func MedianAtEOD(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+	return EOD(Fixing{Location: time.UTC}, MedianAt)(apis, at)
 }
 
 func Median(values []apiOracle.PriceInfo) apiOracle.PriceInfo {
@@ -221,12 +233,12 @@ func Median(values []apiOracle.PriceInfo) apiOracle.PriceInfo {
 	return result
 }
 
-func MeanAt(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64) {
+func MeanAt(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
 	values, confidence := getLatest(apis, at)
 	if confidence == 0 {
-		return apiOracle.PriceInfo{}, 0
+		return apiOracle.PriceInfo{}, 0, 0
 	}
-	return Mean(values), confidence
+	return Mean(values), confidence, dispersion(values)
 }
 
 func Mean(vals []apiOracle.PriceInfo) apiOracle.PriceInfo {
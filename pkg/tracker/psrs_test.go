@@ -5,8 +5,11 @@ package tracker
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/tellor-io/telliot/pkg/apiOracle"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/testutil"
@@ -24,3 +27,32 @@ func TestMeanAt(t *testing.T) {
 
 	MeanAt(ethIndexes, clck.Now())
 }
+
+func TestDispersion(t *testing.T) {
+	now := clck.Now()
+	// A daily fixing an hour ago.
+	fixing := now.Add(-time.Hour).Truncate(time.Second).UTC()
+	y, m, d := fixing.Date()
+	eod := EOD(Fixing{TimeOfDay: fixing.Sub(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)), Location: time.UTC}, MedianAt)
+	var apis []*IndexTracker
+	for i, price := range []float64{100, 101, 99, 130} {
+		api := &IndexTracker{Identifier: fmt.Sprintf("dispersion-%d", i)}
+		apiOracle.SetRequestValue(api.Identifier, fixing, apiOracle.PriceInfo{Price: 100})
+		apiOracle.SetRequestValue(api.Identifier, now, apiOracle.PriceInfo{Price: price})
+		apis = append(apis, api)
+	}
+	// The median of 99, 100, 101 and 130 is 101.
+	_, _, dispersion := MedianAt(apis, now)
+	testutil.Equals(t, 29.0/101, dispersion)
+	_, _, dispersion = MedianAt(apis[:3], now)
+	testutil.Equals(t, 1.0/100, dispersion)
+	_, _, dispersion = MedianAt(apis[:1], now)
+	testutil.Equals(t, 0.0, dispersion)
+	// The outlier rejected by a filter doesn't count.
+	_, _, dispersion = MADFilteredMeanAt(3)(apis, now)
+	testutil.Equals(t, 1.0/100, dispersion)
+	// An end of day value only uses the values at the fixing.
+	_, conf, dispersion := eod(apis, now)
+	testutil.Assert(t, conf > 0, "expected an end of day value")
+	testutil.Equals(t, 0.0, dispersion)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"golang.org/x/time/rate"
+)
+
+// hostLimiter delays the requests to a host so that
+// all its sources together stay below its rate limit.
+type hostLimiter struct {
+	host    string
+	limiter *rate.Limiter
+	mtx     sync.Mutex
+	// blockedUntil is set when the host responds with a Retry-After header
+	// and holds back the requests of all its sources.
+	blockedUntil time.Time
+}
+
+var hostLimiters = struct {
+	sync.Mutex
+	limiters map[string]*hostLimiter
+}{limiters: make(map[string]*hostLimiter)}
+
+// hostLimit is the rate limit of the requests to a host.
+type hostLimit struct {
+	limit rate.Limit
+	burst int
+}
+
+// sourceLimit returns the limit of a source. It comes from the index object when it sets one,
+// then from the host overrides of the config and then from the default of the config.
+func sourceLimit(cfg config.SourceRateLimit, host string, o IndexObject) hostLimit {
+	limit, burst := cfg.Rate, cfg.Burst
+	if h, ok := cfg.Hosts[host]; ok {
+		limit, burst = h.Rate, h.Burst
+	}
+	if o.RateLimit > 0 {
+		limit, burst = o.RateLimit, o.RateBurst
+	}
+	l := hostLimit{limit: rate.Inf, burst: burst}
+	if limit > 0 {
+		l.limit = rate.Limit(limit)
+	}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	return l
+}
+
+// hostLimits returns the limit of every host of the http APIs of the index file.
+// When the sources of a host set different limits the lowest one is used.
+func hostLimits(cfg config.SourceRateLimit, indexes map[string][]IndexObject) map[string]hostLimit {
+	limits := make(map[string]hostLimit)
+	for _, apis := range indexes {
+		for _, api := range apis {
+			if api.Type != "" && api.Type != httpIndexType {
+				continue
+			}
+			u, err := url.Parse(os.Expand(api.URL, os.Getenv))
+			if err != nil {
+				continue
+			}
+			l := sourceLimit(cfg, u.Host, api)
+			current, ok := limits[u.Host]
+			if !ok || l.limit < current.limit || (l.limit == current.limit && l.burst < current.burst) {
+				limits[u.Host] = l
+			}
+		}
+	}
+	return limits
+}
+
+// limiterForHost returns the limiter shared by all the sources of a host and sets its limit.
+// The limit replaces the previous one so that it follows the index file when it is reloaded.
+func limiterForHost(host string, l hostLimit) *hostLimiter {
+	hostLimiters.Lock()
+	defer hostLimiters.Unlock()
+	h, ok := hostLimiters.limiters[host]
+	if !ok {
+		h = &hostLimiter{host: host, limiter: rate.NewLimiter(l.limit, l.burst)}
+		hostLimiters.limiters[host] = h
+		return h
+	}
+	h.limiter.SetLimit(l.limit)
+	h.limiter.SetBurst(l.burst)
+	return h
+}
+
+// Wait blocks until a request to the host is allowed
+// and fails early when that is after the deadline of the context.
+func (l *hostLimiter) Wait(ctx context.Context) error {
+	l.mtx.Lock()
+	blocked := l.blockedUntil.Sub(clck.Now())
+	l.mtx.Unlock()
+	if blocked > 0 {
+		if deadline, ok := ctx.Deadline(); ok && clck.Now().Add(blocked).After(deadline) {
+			return errors.Errorf("host %v asked to retry after the request timeout", l.host)
+		}
+		if err := sleep(ctx, blocked); err != nil {
+			return err
+		}
+	}
+	now := clck.Now()
+	r := l.limiter.ReserveN(now, 1)
+	delay := r.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); !r.OK() || (ok && now.Add(delay).After(deadline)) {
+		r.CancelAt(now)
+		return errors.Errorf("rate limit of host %v exceeds the request timeout", l.host)
+	}
+	if err := sleep(ctx, delay); err != nil {
+		r.CancelAt(clck.Now())
+		return err
+	}
+	return nil
+}
+
+// Block holds back all requests to the host for the given time.
+func (l *hostLimiter) Block(d time.Duration) {
+	until := clck.Now().Add(d)
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
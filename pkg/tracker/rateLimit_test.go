@@ -0,0 +1,100 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"golang.org/x/time/rate"
+)
+
+func TestHostLimiter(t *testing.T) {
+	cfg := config.SourceRateLimit{
+		Rate:  1,
+		Burst: 5,
+		Hosts: map[string]config.HostRateLimit{"override.com": {Rate: 2, Burst: 3}},
+	}
+	limits := hostLimits(cfg, map[string][]IndexObject{
+		"A/USD": {{URL: "https://shared.com/a"}, {URL: "https://override.com/a"}},
+		"B/USD": {{URL: "https://shared.com/b", RateLimit: 0.5, RateBurst: 2}, {URL: "/file.json", Type: fileIndexType}},
+	})
+	testutil.Equals(t, 2, len(limits))
+	a := limiterForHost("shared.com", limits["shared.com"])
+	b := limiterForHost("shared.com", limits["shared.com"])
+	testutil.Assert(t, a == b, "expected a limiter shared by the host")
+	// The lowest limit of a host is used.
+	testutil.Equals(t, rate.Limit(0.5), a.limiter.Limit())
+	testutil.Equals(t, 2, a.limiter.Burst())
+	// The limit follows the index file when the source with the lower limit is removed.
+	limits = hostLimits(cfg, map[string][]IndexObject{"A/USD": {{URL: "https://shared.com/a"}}})
+	testutil.Assert(t, a == limiterForHost("shared.com", limits["shared.com"]), "expected the same limiter after a reload")
+	testutil.Equals(t, rate.Limit(1), a.limiter.Limit())
+	testutil.Equals(t, 5, a.limiter.Burst())
+
+	o := limiterForHost("override.com", sourceLimit(cfg, "override.com", IndexObject{}))
+	testutil.Equals(t, rate.Limit(2), o.limiter.Limit())
+	testutil.Equals(t, 3, o.limiter.Burst())
+
+	// Requests over the burst wait for the rate.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		testutil.Ok(t, o.Wait(ctx))
+	}
+	testutil.NotOk(t, o.Wait(ctx), "expected the limit to exceed the deadline")
+
+	// Requests give up right away when the host asks to retry after their deadline.
+	u := limiterForHost("unlimited.com", sourceLimit(config.SourceRateLimit{}, "unlimited.com", IndexObject{}))
+	testutil.Ok(t, u.Wait(context.Background()))
+	u.Block(time.Minute)
+	start := time.Now()
+	testutil.NotOk(t, u.Wait(ctx), "expected the block to exceed the deadline")
+	testutil.Assert(t, time.Since(start) < 100*time.Millisecond, "waited for a block after the deadline")
+}
+
+// TestShippedIndexesRate checks that the default limits allow
+// every source of the shipped index file to run once per tracker cycle.
+func TestShippedIndexesRate(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	// The default tracker cycle, the test config runs the trackers faster.
+	cycle := 30 * time.Second
+
+	data, err := ioutil.ReadFile(filepath.Join(cfg.ConfigFolder, "indexes.json"))
+	testutil.Ok(t, err)
+	indexes := make(map[string][]IndexObject)
+	testutil.Ok(t, json.Unmarshal(data, &indexes))
+
+	needed := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, apis := range indexes {
+		for _, api := range apis {
+			if seen[api.identifier()] || (api.Type != "" && api.Type != httpIndexType) {
+				continue
+			}
+			seen[api.identifier()] = true
+			u, err := url.Parse(api.URL)
+			testutil.Ok(t, err)
+			interval := cycle
+			if api.Interval.Duration > interval {
+				interval = api.Interval.Duration
+			}
+			needed[u.Host] += 1 / interval.Seconds()
+		}
+	}
+	testutil.Assert(t, needed["api.binance.com"] > 1, "expected the shipped file to need more than 1 request per second to binance")
+
+	limits := hostLimits(cfg.SourceRateLimit, indexes)
+	for host, rps := range needed {
+		l := limiterForHost(host, limits[host])
+		testutil.Assert(t, float64(l.limiter.Limit()) >= rps, "host %v needs %.2f requests per second, limited to %v", host, rps, l.limiter.Limit())
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+var recordLog = util.NewLogger("tracker", "Recorder")
+
+// archiveEntry is a line of an archive with a payload returned by a source
+// or the error of the request.
+type archiveEntry struct {
+	ID string `json:"id"`
+	// Time is when the request started and Latency how long it took.
+	Time    time.Time     `json:"time"`
+	Latency time.Duration `json:"latency"`
+	// Payload holds text payloads and Binary the others so that both are kept exactly.
+	Payload string `json:"payload,omitempty"`
+	Binary  []byte `json:"binary,omitempty"`
+	Err     string `json:"err,omitempty"`
+}
+
+func (e *archiveEntry) payload() ([]byte, error) {
+	if e.Err != "" {
+		return nil, errors.New(e.Err)
+	}
+	if e.Binary != nil {
+		return e.Binary, nil
+	}
+	return []byte(e.Payload), nil
+}
+
+// recorder appends the payloads of the sources to an archive file with a JSON entry per line.
+type recorder struct {
+	mtx  sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+var recorders = struct {
+	sync.Mutex
+	files map[string]*recorder
+}{files: make(map[string]*recorder)}
+
+// openRecorder returns the recorder of an archive file, shared by all the sources.
+// The file is kept open while the process runs.
+func openRecorder(path string) (*recorder, error) {
+	recorders.Lock()
+	defer recorders.Unlock()
+	if r, ok := recorders.files[path]; ok {
+		return r, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening the record file:%v", path)
+	}
+	r := &recorder{file: f, enc: json.NewEncoder(f)}
+	recorders.files[path] = r
+	return r, nil
+}
+
+func (r *recorder) record(id string, start time.Time, latency time.Duration, payload []byte, err error) {
+	e := &archiveEntry{ID: id, Time: start, Latency: latency}
+	switch {
+	case err != nil:
+		e.Err = err.Error()
+	case utf8.Valid(payload):
+		e.Payload = string(payload)
+	default:
+		e.Binary = payload
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err := r.enc.Encode(e); err != nil {
+		recordLog.Error("Writing the payload of %s to the record file: %v", id, err)
+	}
+}
+
+// readArchive returns the entries of an archive file sorted by the time their requests ended.
+func readArchive(path string) ([]*archiveEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening the archive:%v", path)
+	}
+	defer f.Close()
+	var entries []*archiveEntry
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		e := &archiveEntry{}
+		if err := dec.Decode(e); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "decoding entry %v of the archive", len(entries)+1)
+		}
+		entries = append(entries, e)
+	}
+	// Concurrent requests are written in the order they end,
+	// sort them by the time their values are stored.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Add(entries[i].Latency).Before(entries[j].Time.Add(entries[j].Latency))
+	})
+	return entries, nil
+}
+
+// replaySource returns the recorded payload that the replay is at.
+type replaySource struct {
+	mock  *clock.Mock
+	entry *archiveEntry
+}
+
+func (s *replaySource) Get(_ context.Context) ([]byte, error) {
+	// Take as long as the recorded request.
+	s.mock.Add(s.entry.Latency)
+	return s.entry.payload()
+}
+
+// ReplaySummary counts the entries of a replayed archive.
+type ReplaySummary struct {
+	Replayed int
+	// Skipped are the entries of sources that aren't in the index file.
+	Skipped int
+	// Failed are the entries of failed requests or payloads that couldn't be parsed.
+	Failed int
+}
+
+// Replay runs the index trackers of the index file on the payloads of an archive recorded with recordFile,
+// at the times they were recorded, and stores the values of the PSRs in the DB. The trackers and the
+// value history run on a mock clock so the same archive and index file always produce the same values.
+// It replaces the value history with an empty one and must not run together with the trackers.
+func Replay(ctx context.Context, cfg *config.Config, DB db.DB, archive string) (*ReplaySummary, error) {
+	return replay(ctx, cfg, DB, archive, nil)
+}
+
+// replay calls before with the time of every entry before it is replayed
+// and with the zero time after the last one.
+func replay(ctx context.Context, cfg *config.Config, DB db.DB, archive string, before func(time.Time)) (*ReplaySummary, error) {
+	entries, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	// Don't record the replayed payloads again.
+	replayCfg := *cfg
+	replayCfg.RecordFile = ""
+	indexers, symbolIndexes, err := parseIndexFile(&replayCfg, DB, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPSRs(symbolIndexes); err != nil {
+		return nil, errors.Wrap(err, "checking the PSRs")
+	}
+
+	mock := clock.NewMock()
+	defer func(c clock.Clock) { clck = c }(clck)
+	clck = mock
+	apiOracle.InitMemoryValueOracle(mock)
+	setIndexes(symbolIndexes)
+
+	sources := make(map[string]*replaySource)
+	for id, api := range indexers {
+		src := &replaySource{mock: mock}
+		api.Source = src
+		sources[id] = src
+	}
+
+	summary := &ReplaySummary{}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return summary, ctx.Err()
+		}
+		api, ok := indexers[e.ID]
+		if !ok {
+			summary.Skipped++
+			continue
+		}
+		if before != nil {
+			before(e.Time)
+		}
+		mock.Set(e.Time)
+		sources[e.ID].entry = e
+		// Run on every entry, the archive only has the requests that were due.
+		api.lastRunTimestamp = time.Time{}
+		summary.Replayed++
+		if err := api.Exec(ctx); err != nil {
+			summary.Failed++
+			recordLog.Debug("Replaying %s at %v: %v", e.ID, e.Time, err)
+		}
+	}
+	if before != nil {
+		before(time.Time{})
+	}
+	return summary, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// putLog keeps the values the PSRs store in the DB in order.
+type putLog struct {
+	db.DB
+	puts []string
+}
+
+func (p *putLog) Put(key string, value []byte) error {
+	if strings.HasPrefix(key, db.QueriedValuePrefix) || strings.HasPrefix(key, db.QueriedRecordPrefix) {
+		p.puts = append(p.puts, key+"="+string(value))
+	}
+	return p.DB.Put(key, value)
+}
+
+// scriptedSource returns a different price on every request and fails on some.
+type scriptedSource struct {
+	base     float64
+	requests int
+	failOn   int
+}
+
+func (s *scriptedSource) Get(_ context.Context) ([]byte, error) {
+	s.requests++
+	if s.failOn > 0 && s.requests%s.failOn == 0 {
+		return nil, errors.New("source unavailable")
+	}
+	return []byte(fmt.Sprintf(`{"price": %v}`, s.base+float64(s.requests)*0.5)), nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	defer func(old map[int]ValueGenerator) { PSRs = old }(PSRs)
+	PSRs = map[int]ValueGenerator{
+		1: &SingleSymbol{symbol: "ETH/USD", granularity: 1000, transform: MedianAt},
+	}
+	defer func(old map[string][]*IndexTracker) { setIndexes(old) }(GetIndexes())
+	defer func(c clock.Clock) {
+		clck = c
+		apiOracle.InitMemoryValueOracle(c)
+	}(clck)
+
+	dir, err := ioutil.TempDir("", "record")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+	defer func(folder string) { cfg.ConfigFolder = folder }(cfg.ConfigFolder)
+	cfg.ConfigFolder = dir
+	defer func() { cfg.RecordFile = "" }()
+	cfg.RecordFile = filepath.Join(dir, "archive.jsonl")
+
+	data, err := json.Marshal(map[string][]map[string]string{
+		"ETH/USD": {
+			{"URL": "https://a.example.com/price", "param": "$.price"},
+			{"URL": "https://b.example.com/price", "param": "$.price"},
+			{"URL": "https://c.example.com/price", "param": "$.price"},
+		},
+	})
+	testutil.Ok(t, err)
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "indexes.json"), data, 0644))
+
+	// Record a day of requests every 10 minutes.
+	mock := clock.NewMock()
+	mock.Set(time.Date(2021, 1, 29, 0, 0, 0, 0, time.UTC))
+	clck = mock
+	apiOracle.InitMemoryValueOracle(mock)
+	indexers, symbolIndexes, err := parseIndexFile(cfg, DB, nil)
+	testutil.Ok(t, err)
+	setIndexes(symbolIndexes)
+	apis := sortedTrackers(indexers)
+	for i, api := range apis {
+		api.(*IndexTracker).Source = &scriptedSource{base: 100 + float64(i), failOn: 3 * i}
+	}
+	recorded := &putLog{DB: DB}
+	for round := 0; round < 144; round++ {
+		for _, api := range apis {
+			api := api.(*IndexTracker)
+			api.DB = recorded
+			api.lastRunTimestamp = time.Time{}
+			_ = api.Exec(context.Background())
+		}
+		mock.Add(10 * time.Minute)
+	}
+	testutil.Assert(t, len(recorded.puts) > 0, "expected recorded values")
+
+	// Replaying the archive stores the same values in the same order.
+	replayed := &putLog{DB: DB}
+	summary, err := Replay(context.Background(), cfg, replayed, cfg.RecordFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3*144, summary.Replayed)
+	testutil.Equals(t, 0, summary.Skipped)
+	testutil.Equals(t, 144/6+144/3, summary.Failed)
+	testutil.Equals(t, recorded.puts, replayed.puts)
+}
@@ -5,6 +5,7 @@ package tracker
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -28,6 +29,11 @@ type Runner struct {
 	logger       log.Logger
 	config       *config.Config
 	trackerErr   *prometheus.CounterVec
+
+	mtx      sync.Mutex
+	trackers []Tracker
+	// resized notifies the run loop that the number of trackers changed.
+	resized chan struct{}
 }
 
 // NewRunner will create a new runner instance.
@@ -39,6 +45,7 @@ func NewRunner(logger log.Logger, config *config.Config, db db.DB, client rpc.ET
 		contract:     contract,
 		account:      account,
 		readyChannel: make(chan bool, 1),
+		resized:      make(chan struct{}, 1),
 		logger:       log.With(logger, "component", "runner"),
 		trackerErr: promauto.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "telliot",
@@ -72,11 +79,16 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 		return nil
 	}
 
+	r.mtx.Lock()
+	r.trackers = trackers
+	r.mtx.Unlock()
+
 	level.Info(r.logger).Log("msg", "starting trackers", "sleepCycle", r.config.TrackerSleepCycle)
-	ticker := time.NewTicker(r.config.TrackerSleepCycle.Duration / time.Duration(len(trackers)))
+	ticker := time.NewTicker(r.tickInterval(len(trackers)))
 
 	// after first run, let others know that tracker output data is ready for use.
-	doneFirstExec := make(chan bool, len(trackers))
+	firstRound := len(trackers)
+	doneFirstExec := make(chan bool, firstRound)
 	go func(n int) {
 		for i := 0; i < n; i++ {
 			<-doneFirstExec
@@ -96,8 +108,15 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 					ticker.Stop()
 					return
 				}
+			case <-r.resized:
+				{
+					// Keep running every tracker once per cycle.
+					ticker.Stop()
+					ticker = time.NewTicker(r.tickInterval(len(r.running())))
+				}
 			case <-ticker.C:
 				{
+					trackers := r.running()
 					go func(count int) {
 						idx := count % len(trackers)
 						err := trackers[idx].Exec(ctx)
@@ -106,7 +125,7 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 							level.Warn(r.logger).Log("msg", "problem in tracker", "tracker", trackers[idx].String(), "err", err)
 						}
 						// Only the first trackers round execution.
-						if count < len(trackers) {
+						if count < firstRound {
 							doneFirstExec <- true
 						}
 					}(i)
@@ -119,6 +138,57 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 	return nil
 }
 
+func (r *Runner) tickInterval(trackers int) time.Duration {
+	return r.config.TrackerSleepCycle.Duration / time.Duration(trackers)
+}
+
+func (r *Runner) running() []Tracker {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.trackers
+}
+
+// ReloadIndexes parses the index file again and replaces the running index trackers.
+// The trackers of the APIs that didn't change keep running with their history and health.
+// The running trackers aren't changed when the new index file is invalid.
+func (r *Runner) ReloadIndexes() error {
+	if !r.config.Trackers["indexers"] {
+		return errors.New("the indexers tracker isn't running")
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	indexers, err := reloadIndexes(r.config, r.db, r.trackers)
+	if err != nil {
+		return errors.Wrap(err, "reloading the index file")
+	}
+	if len(indexers) == 0 {
+		return errors.New("no APIs in the index file")
+	}
+
+	running := make(map[Tracker]bool)
+	var trackers []Tracker
+	for _, t := range r.trackers {
+		running[t] = true
+		if _, ok := t.(*IndexTracker); !ok {
+			trackers = append(trackers, t)
+		}
+	}
+	var kept int
+	for _, t := range indexers {
+		if running[t] {
+			kept++
+		}
+	}
+	removed := len(r.trackers) - len(trackers) - kept
+	r.trackers = append(trackers, indexers...)
+	select {
+	case r.resized <- struct{}{}:
+	default:
+	}
+	level.Info(r.logger).Log("msg", "reloaded the index file", "kept", kept, "added", len(indexers)-kept, "removed", removed)
+	return nil
+}
+
 // Ready provides notification channel to know that the tracker data output is ready for use.
 func (r *Runner) Ready() chan bool {
 	return r.readyChannel
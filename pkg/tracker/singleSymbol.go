@@ -13,6 +13,8 @@ type SingleSymbol struct {
 	symbol      string
 	granularity float64
 	transform   IndexProcessor
+	// class defaults to ClassSpot.
+	class string
 }
 
 func (s SingleSymbol) Require(at time.Time) map[string]IndexProcessor {
@@ -28,3 +30,10 @@ func (s SingleSymbol) ValueAt(vals map[string]apiOracle.PriceInfo, at time.Time)
 func (s SingleSymbol) Granularity() int64 {
 	return int64(s.granularity)
 }
+
+func (s SingleSymbol) Class() string {
+	if s.class == "" {
+		return ClassSpot
+	}
+	return s.class
+}
@@ -0,0 +1,278 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+var sourceHealthLog = util.NewLogger("tracker", "SourceHealth")
+
+var (
+	sourceQuarantined = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: "tracker",
+		Name:      "source_quarantined",
+		Help:      "Whether the source is excluded from the indexes because it is unhealthy, 1 is quarantined",
+	}, []string{"id"})
+	sourceErrorRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: "tracker",
+		Name:      "source_error_rate",
+		Help:      "The fraction of the latest requests to the source that failed",
+	}, []string{"id"})
+	sourceLatency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: "tracker",
+		Name:      "source_latency_seconds",
+		Help:      "The average duration of the latest requests to the source",
+	}, []string{"id"})
+	sourceStaleness = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: "tracker",
+		Name:      "source_staleness_seconds",
+		Help:      "The age of the latest value of the source",
+	}, []string{"id"})
+	sourceDeviation = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: "tracker",
+		Name:      "source_deviation",
+		Help:      "The average relative deviation of the latest values of the source from the median of the other sources",
+	}, []string{"id"})
+)
+
+// SourceHealth describes how a source behaved over its latest requests.
+type SourceHealth struct {
+	ErrorRate   float64       `json:"errorRate"`
+	Latency     time.Duration `json:"latency"`
+	Staleness   time.Duration `json:"staleness"`
+	Deviation   float64       `json:"deviation"`
+	Quarantined bool          `json:"quarantined"`
+	// Reason explains why the source is quarantined.
+	Reason string `json:"reason,omitempty"`
+}
+
+type fetchResult struct {
+	failed  bool
+	latency time.Duration
+	// Deviation from the other sources, only set for values
+	// of symbols with enough other sources to compare with.
+	deviation    float64
+	hasDeviation bool
+}
+
+// healthMonitor keeps the results of the latest requests to a source
+// and quarantines the source while they exceed the thresholds.
+type healthMonitor struct {
+	cfg      config.SourceHealth
+	interval time.Duration
+	created  time.Time
+
+	mtx         sync.Mutex
+	results     []fetchResult
+	lastSuccess time.Time
+	quarantined bool
+}
+
+func newHealthMonitor(cfg config.SourceHealth, interval time.Duration) *healthMonitor {
+	return &healthMonitor{
+		cfg:      cfg,
+		interval: interval,
+		created:  clck.Now(),
+	}
+}
+
+// record adds the result of a request and returns the health of the source
+// after it and whether the source entered or left the quarantine.
+func (h *healthMonitor) record(r fetchResult, at time.Time) (SourceHealth, bool) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.results = append(h.results, r)
+	window := h.cfg.Window
+	if window < 1 {
+		window = 1
+	}
+	if len(h.results) > window {
+		h.results = h.results[len(h.results)-window:]
+	}
+	if !r.failed {
+		h.lastSuccess = at
+	}
+	s := h.status(at)
+	changed := s.Quarantined != h.quarantined
+	h.quarantined = s.Quarantined
+	return s, changed
+}
+
+// health returns the health of the source at the given time.
+func (h *healthMonitor) health(at time.Time) SourceHealth {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.status(at)
+}
+
+// status must be called with the lock held.
+func (h *healthMonitor) status(at time.Time) SourceHealth {
+	var s SourceHealth
+	var failed, deviations int
+	for _, r := range h.results {
+		if r.failed {
+			failed++
+		}
+		if r.hasDeviation {
+			s.Deviation += r.deviation
+			deviations++
+		}
+		s.Latency += r.latency
+	}
+	if len(h.results) > 0 {
+		s.ErrorRate = float64(failed) / float64(len(h.results))
+		s.Latency /= time.Duration(len(h.results))
+	}
+	if deviations > 0 {
+		s.Deviation /= float64(deviations)
+	}
+	since := h.lastSuccess
+	if since.IsZero() {
+		since = h.created
+	}
+	s.Staleness = at.Sub(since)
+
+	var reasons []string
+	if h.cfg.MaxErrorRate > 0 && s.ErrorRate > h.cfg.MaxErrorRate {
+		reasons = append(reasons, fmt.Sprintf("error rate %.0f%%", s.ErrorRate*100))
+	}
+	if h.cfg.MaxLatency.Duration > 0 && s.Latency > h.cfg.MaxLatency.Duration {
+		reasons = append(reasons, fmt.Sprintf("latency %v", s.Latency.Round(time.Millisecond)))
+	}
+	if h.cfg.MaxStaleness.Duration > 0 && s.Staleness > h.cfg.MaxStaleness.Duration+h.interval {
+		reasons = append(reasons, fmt.Sprintf("no value for %v", s.Staleness.Round(time.Second)))
+	}
+	if h.cfg.MaxDeviation > 0 && s.Deviation > h.cfg.MaxDeviation {
+		reasons = append(reasons, fmt.Sprintf("deviation %.2f%%", s.Deviation*100))
+	}
+	s.Quarantined = len(reasons) > 0
+	s.Reason = strings.Join(reasons, ", ")
+	return s
+}
+
+// Health returns the health of the source or a healthy state when it isn't monitored.
+func (i *IndexTracker) Health(at time.Time) SourceHealth {
+	if i.health == nil {
+		return SourceHealth{}
+	}
+	return i.health.health(at)
+}
+
+// Quarantined reports whether the source is excluded from the indexes at the given time.
+// The health only keeps the latest requests, so for past times only the staleness
+// is judged at that time and the error rate, latency and deviation are the current ones.
+func (i *IndexTracker) Quarantined(at time.Time) bool {
+	return i.Health(at).Quarantined
+}
+
+// recordHealth adds the result of a request to the health of the source
+// and logs when the source enters or leaves the quarantine.
+func (i *IndexTracker) recordHealth(failed bool, latency time.Duration, at time.Time) {
+	if i.health == nil {
+		return
+	}
+	r := fetchResult{failed: failed, latency: latency}
+	if !failed {
+		r.deviation, r.hasDeviation = consensusDeviation(i, at)
+	}
+	s, changed := i.health.record(r, at)
+	if changed {
+		if s.Quarantined {
+			sourceHealthLog.Warn("quarantining source %v:%v", i, s.Reason)
+		} else {
+			sourceHealthLog.Info("source %v is healthy again", i)
+		}
+	}
+	i.updateHealthMetrics(s)
+}
+
+func (i *IndexTracker) updateHealthMetrics(s SourceHealth) {
+	labels := prometheus.Labels{"id": i.String()}
+	quarantined := 0.0
+	if s.Quarantined {
+		quarantined = 1
+	}
+	sourceQuarantined.With(labels).Set(quarantined)
+	sourceErrorRate.With(labels).Set(s.ErrorRate)
+	sourceLatency.With(labels).Set(s.Latency.Seconds())
+	sourceStaleness.With(labels).Set(s.Staleness.Seconds())
+	sourceDeviation.With(labels).Set(s.Deviation)
+}
+
+// deleteHealthMetrics removes the metrics of a source that isn't tracked anymore.
+func (i *IndexTracker) deleteHealthMetrics() {
+	labels := prometheus.Labels{"id": i.String()}
+	sourceQuarantined.Delete(labels)
+	sourceErrorRate.Delete(labels)
+	sourceLatency.Delete(labels)
+	sourceStaleness.Delete(labels)
+	sourceDeviation.Delete(labels)
+}
+
+// consensusDeviation returns the largest relative deviation of the latest value of the API
+// from the median of the recent values of the other healthy sources of its symbols.
+// Symbols with less than 2 other sources are skipped as there is no majority to compare with.
+func consensusDeviation(api *IndexTracker, at time.Time) (float64, bool) {
+	latest, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, at)
+	if latest == nil {
+		return 0, false
+	}
+	var max float64
+	compared := false
+	for _, symbol := range api.Symbols {
+		// Skip the source specific variants of the symbols.
+		if strings.Contains(symbol, "~") {
+			continue
+		}
+		var others []apiOracle.PriceInfo
+		for _, other := range GetIndexes()[symbol] {
+			if other == api || other.Quarantined(at) {
+				continue
+			}
+			b, _ := apiOracle.GetNearestTwoRequestValue(other.Identifier, at)
+			if b != nil && at.Sub(b.Created) <= _sourceMaxAge {
+				others = append(others, b.PriceInfo)
+			}
+		}
+		if len(others) < 2 {
+			continue
+		}
+		median := Median(others).Price
+		if median == 0 {
+			continue
+		}
+		compared = true
+		if d := math.Abs(latest.Price-median) / math.Abs(median); d > max {
+			max = d
+		}
+	}
+	return max, compared
+}
+
+// healthy returns the APIs that aren't quarantined at the given time.
+func healthy(apis []*IndexTracker, at time.Time) []*IndexTracker {
+	result := make([]*IndexTracker, 0, len(apis))
+	for _, api := range apis {
+		if !api.Quarantined(at) {
+			result = append(result, api)
+		}
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type flakySource struct {
+	payload string
+	fail    bool
+}
+
+func (f *flakySource) Get(_ context.Context) ([]byte, error) {
+	if f.fail {
+		return nil, errors.New("source unavailable")
+	}
+	return []byte(f.payload), nil
+}
+
+func TestSourceHealth(t *testing.T) {
+	config.OpenTestConfig(t)
+	defer func(old map[string][]*IndexTracker) { indexes = old }(indexes)
+	indexes = make(map[string][]*IndexTracker)
+
+	cfg := config.SourceHealth{
+		Window:       4,
+		MaxErrorRate: 0.5,
+		MaxDeviation: 0.1,
+	}
+	symbol := t.Name() + "/USD"
+	var sources []*flakySource
+	var apis []*IndexTracker
+	for i, payload := range []string{"100", "101", "99", "150"} {
+		source := &flakySource{payload: payload}
+		api := &IndexTracker{
+			Name:       fmt.Sprintf("source%d", i),
+			Identifier: fmt.Sprintf("%s-%d", t.Name(), i),
+			Symbols:    []string{symbol},
+			Source:     source,
+			health:     newHealthMonitor(cfg, 0),
+		}
+		sources = append(sources, source)
+		apis = append(apis, api)
+		indexes[symbol] = append(indexes[symbol], api)
+	}
+	ctx := context.Background()
+	for _, api := range apis {
+		testutil.Ok(t, api.Exec(ctx))
+	}
+
+	// The last source disagrees with the others.
+	for _, api := range apis[:3] {
+		testutil.Assert(t, !api.Quarantined(clck.Now()), "expected %v to be healthy", api)
+	}
+	testutil.Assert(t, apis[3].Quarantined(clck.Now()), "expected the deviating source to be quarantined")
+	health := apis[3].Health(clck.Now())
+	testutil.Assert(t, health.Deviation > 0.4, "unexpected deviation:%v", health.Deviation)
+	testutil.Assert(t, health.Reason != "", "expected a reason")
+
+	val, _, _ := MeanAt(apis, clck.Now())
+	testutil.Equals(t, 100.0, val.Price)
+	testutil.Equals(t, 3, activeSources(apis, clck.Now()))
+
+	// Failing sources are quarantined until most of the window succeeds again.
+	sources[0].fail = true
+	testutil.NotOk(t, apis[0].Exec(ctx))
+	testutil.Assert(t, !apis[0].Quarantined(clck.Now()), "expected the source to be healthy at 50% errors")
+	testutil.NotOk(t, apis[0].Exec(ctx))
+	testutil.Assert(t, apis[0].Quarantined(clck.Now()), "expected the failing source to be quarantined")
+	testutil.NotOk(t, apis[0].Exec(ctx))
+	testutil.Equals(t, 2, activeSources(apis, clck.Now()))
+
+	sources[0].fail = false
+	testutil.Ok(t, apis[0].Exec(ctx))
+	testutil.Assert(t, apis[0].Quarantined(clck.Now()), "expected the source to stay quarantined")
+	testutil.Ok(t, apis[0].Exec(ctx))
+	testutil.Assert(t, !apis[0].Quarantined(clck.Now()), "expected the source to recover")
+
+	// Sources without enough other sources are never compared.
+	_, compared := consensusDeviation(&IndexTracker{Identifier: apis[1].Identifier, Symbols: []string{"OTHER/USD"}}, clck.Now())
+	testutil.Assert(t, !compared, "expected no consensus without other sources")
+}
+
+func TestSourceHealthStaleness(t *testing.T) {
+	h := newHealthMonitor(config.SourceHealth{Window: 2, MaxStaleness: config.Duration{Duration: time.Minute}}, time.Minute)
+	start := clck.Now()
+	s, changed := h.record(fetchResult{}, start)
+	testutil.Assert(t, !s.Quarantined && !changed, "expected a healthy source")
+
+	// The interval of the source is added to the max staleness.
+	testutil.Assert(t, !h.health(start.Add(90*time.Second)).Quarantined, "expected a healthy source within its interval")
+	testutil.Assert(t, h.health(start.Add(3*time.Minute)).Quarantined, "expected a stale source")
+
+	// Values for past times use the sources that were healthy at that time.
+	apis := []*IndexTracker{{Identifier: "stale", health: h}}
+	testutil.Equals(t, 1, len(healthy(apis, start)))
+	testutil.Equals(t, 0, len(healthy(apis, start.Add(3*time.Minute))))
+}
@@ -5,13 +5,9 @@ package tracker
 
 import (
 	"context"
-	"fmt"
-
 	"encoding/hex"
-	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -68,24 +64,27 @@ func (b *TimeOutTracker) Exec(ctx context.Context) error {
 	//testutil.Ok(t, errors.New(fmt.Spintf("Miner is not able to mine with status %v. Stopping all mining immediately", status)))
 	// }
 
-	//add all whitelisted miner addresses as well since they will be coming in
-	//asking for dispute status
-	for _, addr := range b.config.ServerWhitelist {
-		address := "000000000000000000000000" + addr[2:]
+	// Add the data server account and all whitelisted miner addresses as well
+	// since they will be coming in asking for their own last mined time.
+	addrs, err := minerAddresses(b.config, b.db, b.account)
+	if err != nil {
+		return errors.Wrap(err, "getting the whitelisted miners")
+	}
+	for _, from := range addrs {
+		address := "000000000000000000000000" + from.Hex()[2:]
 		decoded, err := hex.DecodeString(address)
 		if err != nil {
 			return errors.Wrapf(err, "decoding address")
 		}
 		status, err := b.contract.Getter.GetUintVar(nil, rpc.Keccak256(decoded))
 		if err != nil {
-			level.Error(b.logger).Log("msg", "getting staker timeOut status for miner", "address", addr, "err", err)
+			level.Error(b.logger).Log("msg", "getting staker timeOut status for miner", "address", from.Hex(), "err", err)
+			continue
 		}
 		if status.Int64() > 0 {
-			level.Info(b.logger).Log("msg", "whitelisted miner", "addr", addr, "lastTimeMined", time.Unix(status.Int64(), 0))
+			level.Info(b.logger).Log("msg", "miner", "addr", from.Hex(), "lastTimeMined", time.Unix(status.Int64(), 0))
 		}
-		from := common.HexToAddress(addr)
-		dbKey := fmt.Sprintf("%s-%s", strings.ToLower(from.Hex()), db.TimeOutKey)
-		err = b.db.Put(dbKey, []byte(hexutil.EncodeBig(status)))
+		err = b.db.Put(db.MinerKey(from.Hex(), db.TimeOutKey), []byte(hexutil.EncodeBig(status)))
 		if err != nil {
 			return errors.Wrapf(err, "storing last time mined")
 		}
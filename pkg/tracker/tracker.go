@@ -5,6 +5,12 @@ package tracker
 
 import (
 	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 // Tracker is the primary interface for the various tracking options.
@@ -14,3 +20,23 @@ type Tracker interface {
 	Exec(ctx context.Context) error
 	String() string
 }
+
+// minerAddresses returns the data server account followed by all whitelisted miners
+// so that the per miner values are tracked for every miner that can request them.
+func minerAddresses(cfg *config.Config, DB db.DB, account *rpc.Account) ([]common.Address, error) {
+	stored, err := db.StoredWhitelist(DB)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{strings.ToLower(account.Address.Hex()): true}
+	addrs := []common.Address{account.Address}
+	for _, a := range append(append([]string(nil), cfg.ServerWhitelist...), stored...) {
+		addr := common.HexToAddress(a)
+		if seen[strings.ToLower(addr.Hex())] {
+			continue
+		}
+		seen[strings.ToLower(addr.Hex())] = true
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+)
+
+// madScale makes the median absolute deviation comparable to the standard deviation of normally distributed values.
+const madScale = 1.4826
+
+// TrimmedMeanAt returns the mean of the latest values without
+// the given fraction of the lowest and of the highest values.
+// The trimmed values don't count towards the coverage, the agreement and the dispersion.
+func TrimmedMeanAt(fraction float64) IndexProcessor {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+		values, freshness := latestValues(apis, at)
+		if len(values) == 0 {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return values[i].Price < values[j].Price
+		})
+		trim := int(float64(len(values)) * fraction)
+		// Always keep at least one value.
+		if 2*trim >= len(values) {
+			trim = (len(values) - 1) / 2
+		}
+		kept := values[trim : len(values)-trim]
+		return Mean(kept), Confidence(freshness, Coverage(len(kept), len(apis)), Agreement(kept)), dispersion(kept)
+	}
+}
+
+// VolumeWeightedMedianAt returns the latest value at which half of the total volume is reached
+// so that APIs with little volume can't move the result.
+// It is the plain median when none of the APIs reports a volume.
+func VolumeWeightedMedianAt(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+	values, confidence := getLatest(apis, at)
+	if confidence == 0 {
+		return apiOracle.PriceInfo{}, 0, 0
+	}
+	return VolumeWeightedMedian(values), confidence, dispersion(values)
+}
+
+func VolumeWeightedMedian(values []apiOracle.PriceInfo) apiOracle.PriceInfo {
+	var totalVolume float64
+	for _, v := range values {
+		totalVolume += v.Volume
+	}
+	if totalVolume == 0 {
+		return Median(values)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Price < values[j].Price
+	})
+	result := apiOracle.PriceInfo{Volume: totalVolume}
+	var cumulative float64
+	for _, v := range values {
+		cumulative += v.Volume
+		if cumulative >= totalVolume/2 {
+			result.Price = v.Price
+			break
+		}
+	}
+	return result
+}
+
+// MADFilteredMeanAt returns the mean of the latest values that are within the given number
+// of scaled median absolute deviations from the median.
+// The rejected values don't count towards the coverage, the agreement and the dispersion.
+func MADFilteredMeanAt(threshold float64) IndexProcessor {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+		values, freshness := latestValues(apis, at)
+		if len(values) == 0 {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
+		kept := MADFilter(values, threshold)
+		return Mean(kept), Confidence(freshness, Coverage(len(kept), len(apis)), Agreement(kept)), dispersion(kept)
+	}
+}
+
+// MADFilter returns the values within the given number of scaled median absolute deviations from the median.
+// When more than half of the values are equal only the values equal to the median are kept.
+func MADFilter(values []apiOracle.PriceInfo, threshold float64) []apiOracle.PriceInfo {
+	median := Median(values).Price
+	deviations := make([]apiOracle.PriceInfo, len(values))
+	for i, v := range values {
+		deviations[i].Price = math.Abs(v.Price - median)
+	}
+	mad := Median(deviations).Price * madScale
+
+	var kept []apiOracle.PriceInfo
+	for _, v := range values {
+		d := math.Abs(v.Price - median)
+		if d == 0 || (mad > 0 && d/mad <= threshold) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// MinSources makes the processor produce no value while less than the given number of APIs have a recent value.
+func MinSources(n int, processor IndexProcessor) IndexProcessor {
+	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, float64) {
+		if activeSources(apis, at) < n {
+			return apiOracle.PriceInfo{}, 0, 0
+		}
+		return processor(apis, at)
+	}
+}
+
+// activeSources returns the number of healthy APIs with a recent value.
+func activeSources(apis []*IndexTracker, at time.Time) int {
+	n := 0
+	for _, api := range healthy(apis, at) {
+		b, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, at)
+		if b != nil && at.Sub(b.Created) <= _sourceMaxAge {
+			n++
+		}
+	}
+	return n
+}
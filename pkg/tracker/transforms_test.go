@@ -0,0 +1,109 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// fixtureAPIs records a single value for every API in the value oracle.
+func fixtureAPIs(t *testing.T, at time.Time, values ...apiOracle.PriceInfo) []*IndexTracker {
+	apis := make([]*IndexTracker, 0, len(values))
+	for i, v := range values {
+		api := &IndexTracker{Identifier: fmt.Sprintf("%s-%d", t.Name(), i)}
+		apiOracle.SetRequestValue(api.Identifier, at, v)
+		apis = append(apis, api)
+	}
+	return apis
+}
+
+func prices(prices ...float64) []apiOracle.PriceInfo {
+	infos := make([]apiOracle.PriceInfo, len(prices))
+	for i, p := range prices {
+		infos[i].Price = p
+	}
+	return infos
+}
+
+func TestTrimmedMeanAt(t *testing.T) {
+	now := clck.Now()
+	apis := fixtureAPIs(t, now, prices(1, 100, 102, 104, 1000)...)
+
+	val, conf, _ := TrimmedMeanAt(0.2)(apis, now)
+	testutil.Equals(t, 102.0, val.Price)
+	testutil.Assert(t, conf > 0, "expected a confidence")
+
+	// Nothing is trimmed.
+	val, _, _ = TrimmedMeanAt(0)(apis, now)
+	testutil.Equals(t, 261.4, val.Price)
+
+	// At least the middle value is kept.
+	val, _, _ = TrimmedMeanAt(0.5)(apis, now)
+	testutil.Equals(t, 102.0, val.Price)
+
+	val, conf, _ = TrimmedMeanAt(0.2)(nil, now)
+	testutil.Equals(t, 0.0, conf)
+	testutil.Equals(t, 0.0, val.Price)
+}
+
+func TestVolumeWeightedMedianAt(t *testing.T) {
+	now := clck.Now()
+	apis := fixtureAPIs(t, now,
+		apiOracle.PriceInfo{Price: 100, Volume: 1000},
+		apiOracle.PriceInfo{Price: 101, Volume: 2000},
+		apiOracle.PriceInfo{Price: 150, Volume: 10},
+		apiOracle.PriceInfo{Price: 160, Volume: 10},
+		apiOracle.PriceInfo{Price: 170, Volume: 10},
+	)
+	// The plain median would be 150.
+	val, conf, _ := VolumeWeightedMedianAt(apis, now)
+	testutil.Equals(t, 101.0, val.Price)
+	testutil.Equals(t, 3030.0, val.Volume)
+	testutil.Assert(t, conf > 0, "expected a confidence")
+
+	// Without volumes it is the plain median.
+	apis = fixtureAPIs(t, now, prices(100, 101, 150, 160, 170)...)
+	val, _, _ = VolumeWeightedMedianAt(apis, now)
+	testutil.Equals(t, 150.0, val.Price)
+}
+
+func TestMADFilteredMeanAt(t *testing.T) {
+	now := clck.Now()
+	apis := fixtureAPIs(t, now, prices(100, 101, 99, 100, 500)...)
+
+	val, conf, _ := MADFilteredMeanAt(3)(apis, now)
+	testutil.Equals(t, 100.0, val.Price)
+	// The rejected value lowers the coverage but not the agreement.
+	testutil.Equals(t, Confidence(1, 0.8, 0.99), conf)
+
+	// Agreeing values are all kept.
+	apis = fixtureAPIs(t, now, prices(100, 102)...)
+	val, conf, _ = MADFilteredMeanAt(3)(apis, now)
+	testutil.Equals(t, 101.0, val.Price)
+	_, allConf, _ := MeanAt(apis, now)
+	testutil.Equals(t, allConf, conf)
+
+	// Only the values equal to the median are kept when most values are equal.
+	testutil.Equals(t, prices(100, 100, 100), MADFilter(prices(100, 100, 100, 101, 50), 3))
+}
+
+func TestMinSources(t *testing.T) {
+	now := clck.Now()
+	apis := fixtureAPIs(t, now, prices(100, 102)...)
+	old := &IndexTracker{Identifier: t.Name() + "-old"}
+	apiOracle.SetRequestValue(old.Identifier, now.Add(-time.Hour), apiOracle.PriceInfo{Price: 100})
+	apis = append(apis, old)
+
+	_, conf, _ := MinSources(2, MedianAt)(apis, now)
+	testutil.Assert(t, conf > 0, "expected a value from 2 sources")
+
+	// The old value doesn't count as a source.
+	_, conf, _ = MinSources(3, MedianAt)(apis, now)
+	testutil.Equals(t, 0.0, conf)
+}
@@ -11,12 +11,14 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 type TributeTracker struct {
+	config   *config.Config
 	db       db.DB
 	contract *contracts.Tellor
 	account  *rpc.Account
@@ -27,8 +29,9 @@ func (b *TributeTracker) String() string {
 	return "TributeTracker"
 }
 
-func NewTributeTracker(logger log.Logger, db db.DB, contract *contracts.Tellor, account *rpc.Account) *TributeTracker {
+func NewTributeTracker(logger log.Logger, config *config.Config, db db.DB, contract *contracts.Tellor, account *rpc.Account) *TributeTracker {
 	return &TributeTracker{
+		config:   config,
 		db:       db,
 		contract: contract,
 		account:  account,
@@ -37,19 +40,36 @@ func NewTributeTracker(logger log.Logger, db db.DB, contract *contracts.Tellor,
 }
 
 func (b *TributeTracker) Exec(ctx context.Context) error {
-	balance, err := b.contract.Getter.BalanceOf(nil, b.account.Address)
-	balanceInTributes, _ := big.NewFloat(1).SetString(balance.String())
-
-	decimals, _ := big.NewFloat(1).SetString("1000000000000000000")
-
-	if decimals != nil {
-		balanceInTributes = balanceInTributes.Quo(balanceInTributes, decimals)
-	}
-
-	level.Debug(b.logger).Log("msg", "tribute balance", "raw", balance, "trb", balanceInTributes)
+	// Every miner gets its own balance, the first one is the data server account.
+	addrs, err := minerAddresses(b.config, b.db, b.account)
 	if err != nil {
-		return errors.Wrap(err, "retrieving balance")
+		return errors.Wrap(err, "getting the whitelisted miners")
+	}
+	for _, addr := range addrs {
+		balance, err := b.contract.Getter.BalanceOf(nil, addr)
+		if err != nil {
+			if addr == b.account.Address {
+				return errors.Wrap(err, "retrieving balance")
+			}
+			level.Error(b.logger).Log("msg", "getting tribute balance for miner", "address", addr.Hex(), "err", err)
+			continue
+		}
+		enc := []byte(hexutil.EncodeBig(balance))
+		if addr == b.account.Address {
+			balanceInTributes, _ := big.NewFloat(1).SetString(balance.String())
+			decimals, _ := big.NewFloat(1).SetString("1000000000000000000")
+			if decimals != nil {
+				balanceInTributes = balanceInTributes.Quo(balanceInTributes, decimals)
+			}
+			level.Debug(b.logger).Log("msg", "tribute balance", "raw", balance, "trb", balanceInTributes)
+			// The legacy key is the balance of the data server account.
+			if err := b.db.Put(db.TributeBalanceKey, enc); err != nil {
+				return errors.Wrap(err, "storing balance")
+			}
+		}
+		if err := b.db.Put(db.MinerKey(addr.Hex(), db.TributeBalanceKey), enc); err != nil {
+			return errors.Wrap(err, "storing miner tribute balance")
+		}
 	}
-	enc := hexutil.EncodeBig(balance)
-	return b.db.Put(db.TributeBalanceKey, []byte(enc))
+	return nil
 }
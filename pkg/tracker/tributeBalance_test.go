@@ -34,7 +34,7 @@ func TestTributeBalance(t *testing.T) {
 	testutil.Ok(t, err)
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewTributeTracker(logger, DB, &contract, &account)
+	tracker := NewTributeTracker(logger, cfg, DB, &contract, &account)
 	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
 	v, err := DB.Get(db.TributeBalanceKey)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -17,8 +18,13 @@ import (
 	"github.com/tellor-io/telliot/pkg/db"
 )
 
+// APIs with no value for longer than this don't count as sources of a PSR value.
+const _sourceMaxAge = 5 * time.Minute
+
 // IndexProcessor consolidates the recorded API values to a single value.
-type IndexProcessor func([]*IndexTracker, time.Time) (apiOracle.PriceInfo, float64)
+// It returns the value, its confidence and the dispersion of the source values it used,
+// the largest relative deviation of one of them from their median.
+type IndexProcessor func([]*IndexTracker, time.Time) (apiOracle.PriceInfo, float64, float64)
 
 type ValueGenerator interface {
 	// Require reports what a PSR requires to produce a value.
@@ -33,7 +39,11 @@ type ValueGenerator interface {
 }
 
 func InitPSRs() error {
-	//check that we have all the symbols asked for
+	return checkPSRs(GetIndexes())
+}
+
+// checkPSRs checks that the indexes have all the symbols required by the PSRs.
+func checkPSRs(indexes map[string][]*IndexTracker) error {
 	now := clck.Now()
 	for requestID, handler := range PSRs {
 		reqs := handler.Require(now)
@@ -47,24 +57,101 @@ func InitPSRs() error {
 	return nil
 }
 
-func PSRValueForTime(requestID int, at time.Time) (float64, float64) {
+// PSRValueForTime returns the value of the request ID at the given time, its confidence
+// and the largest dispersion of the source values of its symbols used for the value.
+func PSRValueForTime(requestID int, at time.Time) (float64, float64, float64) {
 	// Get the requirements.
 	reqs := PSRs[requestID].Require(at)
+	if len(reqs) == 0 {
+		return 0, 0, 0
+	}
 	values := make(map[string]apiOracle.PriceInfo)
 	minConfidence := math.MaxFloat64
+	maxDispersion := 0.0
+	indexes := GetIndexes()
 
 	for symbol, fn := range reqs {
-		val, confidence := fn(indexes[symbol], at)
+		val, confidence, dispersion := fn(indexes[symbol], at)
 		if confidence == 0 {
-			return 0, 0
+			return 0, 0, 0
 		}
 		if confidence < minConfidence {
 			minConfidence = confidence
 		}
+		if dispersion > maxDispersion {
+			maxDispersion = dispersion
+		}
 		values[symbol] = val
 	}
 
-	return PSRs[requestID].ValueAt(values, at), minConfidence
+	return PSRs[requestID].ValueAt(values, at), minConfidence, maxDispersion
+}
+
+// psrSourceCount returns the number of APIs with a recent value for the least covered symbol of the PSR.
+func psrSourceCount(requestID int, at time.Time) int {
+	count := math.MaxInt32
+	for symbol := range PSRs[requestID].Require(at) {
+		if n := activeSources(GetIndexes()[symbol], at); n < count {
+			count = n
+		}
+	}
+	if count == math.MaxInt32 {
+		return 0
+	}
+	return count
+}
+
+// Classes of PSRs by how their values are computed.
+const (
+	// ClassSpot PSRs compute the current price.
+	ClassSpot = "spot"
+	// ClassAverage PSRs compute an average over an interval.
+	ClassAverage = "average"
+	// ClassEOD PSRs compute a daily fixing.
+	ClassEOD = "eod"
+	// ClassManual PSRs use values entered by the operators.
+	ClassManual = "manual"
+)
+
+// PSRClass returns the class of the PSR of the request ID or an empty string when there is none.
+func PSRClass(requestID int) string {
+	psr, ok := PSRs[requestID]
+	if !ok {
+		return ""
+	}
+	if c, ok := psr.(interface{ Class() string }); ok {
+		return c.Class()
+	}
+	return ClassSpot
+}
+
+// PSRSymbols returns the symbols used to compute the value of the request ID.
+func PSRSymbols(requestID int, at time.Time) []string {
+	psr, ok := PSRs[requestID]
+	if !ok {
+		return nil
+	}
+	var symbols []string
+	for symbol := range psr.Require(at) {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// PSRSources returns the latest value of every API used for the request ID
+// keyed by the symbol and the API name.
+func PSRSources(requestID int, at time.Time) map[string]float64 {
+	sources := make(map[string]float64)
+	for _, symbol := range PSRSymbols(requestID, at) {
+		for _, api := range GetIndexes()[symbol] {
+			b, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, at)
+			if b != nil {
+				sources[symbol+"~"+api.Name] = b.Price
+			}
+		}
+	}
+	return sources
 }
 
 func UpdatePSRs(ctx context.Context, DB db.DB, updatedSymbols []string) error {
@@ -84,7 +171,7 @@ func UpdatePSRs(ctx context.Context, DB db.DB, updatedSymbols []string) error {
 
 	// Update all affected PSRs.
 	for _, requestID := range toUpdate {
-		amt, conf := PSRValueForTime(requestID, now)
+		amt, conf, dispersion := PSRValueForTime(requestID, now)
 		cfg := config.GetConfig()
 		if conf < cfg.MinConfidence || math.IsNaN(amt) {
 			// Confidence in this signal is too low to use.
@@ -96,9 +183,24 @@ func UpdatePSRs(ctx context.Context, DB db.DB, updatedSymbols []string) error {
 		bigVal.SetFloat64(amt)
 		bigInt := new(big.Int)
 		bigVal.Int(bigInt)
+		// Store the record with the details that miners check before submitting.
+		rec, err := db.EncodeValueRecord(&db.ValueRecord{
+			Value:        (*hexutil.Big)(bigInt),
+			Time:         now,
+			Confidence:   conf,
+			Sources:      psrSourceCount(requestID, now),
+			Dispersion:   dispersion,
+			SourceValues: PSRSources(requestID, now),
+		})
+		if err != nil {
+			return err
+		}
+		if err := DB.Put(db.ValueRecordKey(uint64(requestID)), rec); err != nil {
+			return err
+		}
 		// Encode it and store to DB.
 		enc := hexutil.EncodeBig(bigInt)
-		err := DB.Put(fmt.Sprintf("%s%d", db.QueriedValuePrefix, requestID), []byte(enc))
+		err = DB.Put(fmt.Sprintf("%s%d", db.QueriedValuePrefix, requestID), []byte(enc))
 		if err != nil {
 			return err
 		}